@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupPassword string
+	backupParts    []string
+	backupOutput   string
+	backupMode     string
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Export or import storages, users, metas and settings",
+}
+
+var exportBackupCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the configuration to a backup file",
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		defer Release()
+		b, err := backup.Export(backupParts)
+		if err != nil {
+			utils.Log.Errorf("failed export: %+v", err)
+			return
+		}
+		data, err := backup.Marshal(b, backupPassword)
+		if err != nil {
+			utils.Log.Errorf("failed marshal backup: %+v", err)
+			return
+		}
+		output := backupOutput
+		if output == "" {
+			output = fmt.Sprintf("alist-backup-%s.bak", time.Now().Format("20060102150405"))
+		}
+		if err = os.WriteFile(output, data, 0600); err != nil {
+			utils.Log.Errorf("failed write backup: %+v", err)
+			return
+		}
+		utils.Log.Infof("backup of [%s] has been exported to %s", strings.Join(b.Parts, ","), output)
+	},
+}
+
+var importBackupCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import the configuration from a backup file",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("backup file is required")
+			return
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			utils.Log.Errorf("failed read backup: %+v", err)
+			return
+		}
+		Init()
+		defer Release()
+		b, err := backup.Unmarshal(data, backupPassword)
+		if err != nil {
+			utils.Log.Errorf("failed unmarshal backup: %+v", err)
+			return
+		}
+		res, err := backup.Import(context.Background(), b, backup.ImportOptions{
+			Parts: backupParts,
+			Mode:  backupMode,
+		})
+		if err != nil {
+			utils.Log.Errorf("failed import: %+v", err)
+			return
+		}
+		for _, item := range res.Errors {
+			utils.Log.Errorf("failed import %s", item)
+		}
+		utils.Log.Infof("backup has been imported: %d created, %d updated, %d deleted, %d failed",
+			len(res.Created), len(res.Updated), len(res.Deleted), len(res.Errors))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(exportBackupCmd)
+	backupCmd.AddCommand(importBackupCmd)
+	backupCmd.PersistentFlags().StringVarP(&backupPassword, "password", "p", "", "password to encrypt or decrypt the backup")
+	backupCmd.PersistentFlags().StringSliceVar(&backupParts, "parts", nil,
+		"parts to export or import, comma separated, default all of "+strings.Join(backup.AllParts, ","))
+	exportBackupCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "output file")
+	importBackupCmd.Flags().StringVar(&backupMode, "mode", backup.ModeMerge,
+		"merge: add or overwrite items, replace: also delete items not in the backup")
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	stdpath "path"
+	"path/filepath"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// fsCmd represents the fs command
+var fsCmd = &cobra.Command{
+	Use:   "fs",
+	Short: "Operate files through the storages, without the server running",
+}
+
+// initFs loads the enabled storages and returns a context acting as the admin
+func initFs() (context.Context, error) {
+	Init()
+	storages, err := db.GetEnabledStorages()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	for i := range storages {
+		if err = op.LoadStorage(ctx, storages[i]); err != nil {
+			utils.Log.Warnf("failed load storage [%s]: %s", storages[i].MountPath, err)
+		}
+	}
+	conf.StoragesLoaded = true
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, "user", admin)
+	// copy files directly instead of adding tasks, since the task managers are not running
+	return context.WithValue(ctx, conf.NoTaskKey, struct{}{}), nil
+}
+
+// runFs wraps the command with initFs and Release
+func runFs(minArgs int, usage string, f func(ctx context.Context, args []string) error) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if len(args) < minArgs {
+			utils.Log.Errorf("usage: alist fs %s", usage)
+			return
+		}
+		ctx, err := initFs()
+		defer Release()
+		if err == nil {
+			err = f(ctx, args)
+		}
+		if err != nil {
+			utils.Log.Errorf("%+v", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// copyTo copies the object to dstDir, dirs between two storages are copied file by file
+func copyTo(ctx context.Context, srcPath, dstDir string) error {
+	obj, err := fs.Get(ctx, srcPath, &fs.GetArgs{})
+	if err != nil {
+		return err
+	}
+	srcStorage, err := fs.GetStorage(srcPath, &fs.GetStoragesArgs{})
+	if err != nil {
+		return err
+	}
+	dstStorage, err := fs.GetStorage(dstDir, &fs.GetStoragesArgs{})
+	if err != nil {
+		return err
+	}
+	if !obj.IsDir() || srcStorage.GetStorage() == dstStorage.GetStorage() {
+		_, err = fs.Copy(ctx, srcPath, dstDir)
+		return err
+	}
+	dst := stdpath.Join(dstDir, obj.GetName())
+	if err = fs.MakeDir(ctx, dst); err != nil {
+		return err
+	}
+	objs, err := fs.List(ctx, srcPath, &fs.ListArgs{})
+	if err != nil {
+		return err
+	}
+	for _, o := range objs {
+		if err = copyTo(ctx, stdpath.Join(srcPath, o.GetName()), dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls PATH",
+	Short: "List a directory",
+	Run: runFs(1, "ls PATH", func(ctx context.Context, args []string) error {
+		objs, err := fs.List(ctx, args[0], &fs.ListArgs{})
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			t, size := "-", fmt.Sprint(obj.GetSize())
+			if obj.IsDir() {
+				t, size = "d", "-"
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", t, size, obj.ModTime().Format("2006-01-02 15:04:05"), obj.GetName())
+		}
+		return nil
+	}),
+}
+
+var cpCmd = &cobra.Command{
+	Use:   "cp SRC... DST_DIR",
+	Short: "Copy files or directories into a directory",
+	Run: runFs(2, "cp SRC... DST_DIR", func(ctx context.Context, args []string) error {
+		dstDir := args[len(args)-1]
+		for _, src := range args[:len(args)-1] {
+			if err := copyTo(ctx, src, dstDir); err != nil {
+				return errors.WithMessagef(err, "failed copy %s", src)
+			}
+		}
+		return nil
+	}),
+}
+
+var mvCmd = &cobra.Command{
+	Use:   "mv SRC... DST_DIR",
+	Short: "Move files or directories into a directory",
+	Run: runFs(2, "mv SRC... DST_DIR", func(ctx context.Context, args []string) error {
+		dstDir := args[len(args)-1]
+		for _, src := range args[:len(args)-1] {
+			err := fs.Move(ctx, src, dstDir)
+			if errors.Is(err, errs.MoveBetweenTwoStorages) {
+				if err = copyTo(ctx, src, dstDir); err == nil {
+					err = fs.Remove(ctx, src)
+				}
+			}
+			if err != nil {
+				return errors.WithMessagef(err, "failed move %s", src)
+			}
+		}
+		return nil
+	}),
+}
+
+var rmCmd = &cobra.Command{
+	Use:   "rm PATH...",
+	Short: "Remove files or directories",
+	Run: runFs(1, "rm PATH...", func(ctx context.Context, args []string) error {
+		for _, path := range args {
+			if err := fs.Remove(ctx, path); err != nil {
+				return errors.WithMessagef(err, "failed remove %s", path)
+			}
+		}
+		return nil
+	}),
+}
+
+var catCmd = &cobra.Command{
+	Use:   "cat FILE",
+	Short: "Print the content of a file",
+	Run: runFs(1, "cat FILE", func(ctx context.Context, args []string) error {
+		link, obj, err := fs.Link(ctx, args[0], model.LinkArgs{})
+		if err != nil {
+			return err
+		}
+		ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: ctx}, link)
+		if err != nil {
+			return err
+		}
+		defer ss.Close()
+		_, err = io.Copy(os.Stdout, ss)
+		return errors.WithStack(err)
+	}),
+}
+
+var putCmd = &cobra.Command{
+	Use:   "put LOCAL_FILE... DST_DIR",
+	Short: "Upload local files into a directory",
+	Run: runFs(2, "put LOCAL_FILE... DST_DIR", func(ctx context.Context, args []string) error {
+		dstDir := args[len(args)-1]
+		for _, local := range args[:len(args)-1] {
+			if err := putFile(ctx, local, dstDir); err != nil {
+				return errors.WithMessagef(err, "failed put %s", local)
+			}
+		}
+		return nil
+	}),
+}
+
+func putFile(ctx context.Context, local, dstDir string) error {
+	f, err := os.Open(local)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if stat.IsDir() {
+		return errors.New("directories are not supported")
+	}
+	name := filepath.Base(local)
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     stat.Size(),
+			Modified: stat.ModTime(),
+		},
+		Reader:   f,
+		Mimetype: utils.GetMimeType(name),
+	}
+	return fs.PutDirectly(ctx, dstDir, s)
+}
+
+func init() {
+	RootCmd.AddCommand(fsCmd)
+	fsCmd.AddCommand(lsCmd)
+	fsCmd.AddCommand(cpCmd)
+	fsCmd.AddCommand(mvCmd)
+	fsCmd.AddCommand(rmCmd)
+	fsCmd.AddCommand(catCmd)
+	fsCmd.AddCommand(putCmd)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/provision"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var provisionDryRun bool
+
+// provisionCmd represents the provision command
+var provisionCmd = &cobra.Command{
+	Use:   "provision [FILE]",
+	Short: "Reconcile storages, metas and users with a declarative yaml file",
+	Long: `Reconcile storages, metas and users with a declarative yaml file,
+the file defaults to the provision in config file, which is also applied on every start`,
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		defer Release()
+		path := conf.Conf.Provision
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if path == "" {
+			utils.Log.Errorf("provision file is required")
+			return
+		}
+		f, err := provision.Load(path)
+		if err != nil {
+			utils.Log.Errorf("failed load provision file: %+v", err)
+			return
+		}
+		changes, err := provision.Plan(f)
+		if err != nil {
+			utils.Log.Errorf("failed plan provision: %+v", err)
+			return
+		}
+		if len(changes) == 0 {
+			utils.Log.Infof("everything is up to date")
+			return
+		}
+		for _, c := range changes {
+			utils.Log.Infof("%s", c)
+		}
+		if provisionDryRun {
+			return
+		}
+		if err = provision.Apply(changes); err != nil {
+			utils.Log.Errorf("failed apply provision: %+v", err)
+			return
+		}
+		utils.Log.Infof("%d changes have been applied", len(changes))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(provisionCmd)
+	provisionCmd.Flags().BoolVar(&provisionDryRun, "dry-run", false, "only print the changes")
+}
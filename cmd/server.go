@@ -40,6 +40,10 @@ the address is defined in config file`,
 		bootstrap.InitOfflineDownloadTools()
 		bootstrap.LoadStorages()
 		bootstrap.InitTaskManager()
+		bootstrap.InitTrash()
+		bootstrap.InitHealthCheck()
+		bootstrap.InitSubscriptions()
+		bootstrap.InitSyncJobs()
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
 		}
@@ -4,14 +4,20 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/provision"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -47,17 +53,239 @@ var disableStorageCmd = &cobra.Command{
 	},
 }
 
+var (
+	storageDriver   string
+	storageSets     []string
+	storageAddition []string
+)
+
+// parseStorageFields converts key=value pairs to a map, the values are typed by the driver items
+func parseStorageFields(items []driver.Item, kvs []string) (map[string]any, error) {
+	types := make(map[string]string)
+	for _, item := range items {
+		types[item.Name] = item.Type
+	}
+	res := make(map[string]any)
+	for _, kv := range kvs {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid field [%s], should be key=value", kv)
+		}
+		t, ok := types[k]
+		if !ok {
+			return nil, errors.Errorf("unknown field [%s]", k)
+		}
+		var err error
+		switch t {
+		case "number":
+			if res[k], err = strconv.ParseInt(v, 10, 64); err != nil {
+				res[k], err = strconv.ParseFloat(v, 64)
+			}
+		case "bool":
+			res[k], err = strconv.ParseBool(v)
+		default:
+			res[k] = v
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of [%s]", k)
+		}
+	}
+	return res, nil
+}
+
+// saveStorage creates or updates the storage through provision, so the defaults of the driver are filled
+func saveStorage(mountPath string, create bool) {
+	Init()
+	defer Release()
+	old, err := db.GetStorageByMountPath(mountPath)
+	if create && err == nil {
+		utils.Log.Errorf("storage with mount path [%s] already exists", mountPath)
+		return
+	}
+	if !create {
+		if err != nil {
+			utils.Log.Errorf("failed to query storage: %+v", err)
+			return
+		}
+		if storageDriver == "" {
+			storageDriver = old.Driver
+		}
+	}
+	info, ok := op.GetDriverInfoMap()[storageDriver]
+	if !ok {
+		utils.Log.Errorf("driver [%s] not found, see `alist storage drivers`", storageDriver)
+		return
+	}
+	item, err := parseStorageFields(info.Common, storageSets)
+	if err != nil {
+		utils.Log.Errorf("%+v", err)
+		return
+	}
+	addition, err := parseStorageFields(info.Additional, storageAddition)
+	if err != nil {
+		utils.Log.Errorf("%+v", err)
+		return
+	}
+	item["mount_path"], item["driver"], item["addition"] = mountPath, storageDriver, addition
+	changes, err := provision.Plan(&provision.File{Storages: []map[string]any{item}})
+	if err != nil {
+		utils.Log.Errorf("%+v", err)
+		return
+	}
+	if len(changes) == 0 {
+		utils.Log.Infof("Storage with mount path [%s] is up to date", mountPath)
+		return
+	}
+	if err = provision.Apply(changes); err != nil {
+		utils.Log.Errorf("failed to save storage: %+v", err)
+		return
+	}
+	for _, c := range changes {
+		utils.Log.Infof("%s", c)
+	}
+}
+
+var createStorageCmd = &cobra.Command{
+	Use:   "create MOUNT_PATH",
+	Short: "Create a storage",
+	Example: `  alist storage create /local --driver Local -a root_folder_path=/data
+  alist storage create /s3 --driver S3 -a bucket=b -a endpoint=https://s3.example.com --set order=1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("mount path is required")
+			return
+		}
+		if storageDriver == "" {
+			utils.Log.Errorf("driver is required")
+			return
+		}
+		saveStorage(args[0], true)
+	},
+}
+
+var updateStorageCmd = &cobra.Command{
+	Use:   "update MOUNT_PATH",
+	Short: "Update fields of a storage, the others are kept",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("mount path is required")
+			return
+		}
+		saveStorage(args[0], false)
+	},
+}
+
+var enableStorageCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Enable a storage",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("mount path is required")
+			return
+		}
+		mountPath := args[0]
+		Init()
+		defer Release()
+		storage, err := db.GetStorageByMountPath(mountPath)
+		if err != nil {
+			utils.Log.Errorf("failed to query storage: %+v", err)
+			return
+		}
+		storage.Disabled = false
+		if err = db.UpdateStorage(storage); err != nil {
+			utils.Log.Errorf("failed to update storage: %+v", err)
+			return
+		}
+		utils.Log.Infof("Storage with mount path [%s] have been enabled", mountPath)
+	},
+}
+
+var reloadStorageCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Initialize a storage to check it works and save its status",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("mount path is required")
+			return
+		}
+		mountPath := args[0]
+		Init()
+		defer Release()
+		storage, err := db.GetStorageByMountPath(mountPath)
+		if err != nil {
+			utils.Log.Errorf("failed to query storage: %+v", err)
+			return
+		}
+		if err = op.LoadStorage(context.Background(), *storage); err != nil {
+			utils.Log.Errorf("failed to load storage: %+v", err)
+			return
+		}
+		utils.Log.Infof("Storage with mount path [%s] works", mountPath)
+	},
+}
+
+var deleteStorageCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a storage",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("mount path is required")
+			return
+		}
+		mountPath := args[0]
+		Init()
+		defer Release()
+		storage, err := db.GetStorageByMountPath(mountPath)
+		if err != nil {
+			utils.Log.Errorf("failed to query storage: %+v", err)
+			return
+		}
+		if err = db.DeleteStorageById(storage.ID); err != nil {
+			utils.Log.Errorf("failed to delete storage: %+v", err)
+			return
+		}
+		utils.Log.Infof("Storage with mount path [%s] have been deleted", mountPath)
+	},
+}
+
+var driversStorageCmd = &cobra.Command{
+	Use:   "drivers [DRIVER]",
+	Short: "List drivers, or the fields of a driver",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			for _, name := range op.GetDriverNames() {
+				utils.Log.Infof("%s", name)
+			}
+			return
+		}
+		info, ok := op.GetDriverInfoMap()[args[0]]
+		if !ok {
+			utils.Log.Errorf("driver [%s] not found", args[0])
+			return
+		}
+		for _, group := range []struct {
+			flag  string
+			items []driver.Item
+		}{{"--set", info.Common}, {"--addition", info.Additional}} {
+			for _, item := range group.items {
+				utils.Log.Infof("%s %s=<%s> required=%t default=%q %s",
+					group.flag, item.Name, item.Type, item.Required, item.Default, item.Help)
+			}
+		}
+	},
+}
+
 var baseStyle = lipgloss.NewStyle().
 	BorderStyle(lipgloss.NormalBorder()).
 	BorderForeground(lipgloss.Color("240"))
 
-type model struct {
+type tableModel struct {
 	table table.Model
 }
 
-func (m model) Init() tea.Cmd { return nil }
+func (m tableModel) Init() tea.Cmd { return nil }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -80,7 +308,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m model) View() string {
+func (m tableModel) View() string {
 	return baseStyle.Render(m.table.View()) + "\n"
 }
 
@@ -136,7 +364,7 @@ var listStorageCmd = &cobra.Command{
 				Bold(false)
 			t.SetStyles(s)
 
-			m := model{t}
+			m := tableModel{t}
 			if _, err := tea.NewProgram(m).Run(); err != nil {
 				utils.Log.Errorf("failed to run program: %+v", err)
 				os.Exit(1)
@@ -150,6 +378,17 @@ func init() {
 	RootCmd.AddCommand(storageCmd)
 	storageCmd.AddCommand(disableStorageCmd)
 	storageCmd.AddCommand(listStorageCmd)
+	storageCmd.AddCommand(createStorageCmd)
+	storageCmd.AddCommand(updateStorageCmd)
+	storageCmd.AddCommand(enableStorageCmd)
+	storageCmd.AddCommand(reloadStorageCmd)
+	storageCmd.AddCommand(deleteStorageCmd)
+	storageCmd.AddCommand(driversStorageCmd)
+	for _, c := range []*cobra.Command{createStorageCmd, updateStorageCmd} {
+		c.Flags().StringVarP(&storageDriver, "driver", "d", "", "driver of the storage")
+		c.Flags().StringArrayVarP(&storageSets, "set", "s", nil, "common field of the storage, key=value")
+		c.Flags().StringArrayVarP(&storageAddition, "addition", "a", nil, "addition field of the driver, key=value")
+	}
 	storageCmd.PersistentFlags().IntVarP(&storageTableHeight, "height", "H", 10, "Table height")
 	// Here you will define your flags and configuration settings.
 
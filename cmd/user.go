@@ -3,15 +3,176 @@ package cmd
 import (
 	"crypto/tls"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-resty/resty/v2"
+	"github.com/spf13/cobra"
 )
 
+// permNames are the names of the permission bits of model.User, in order
+var permNames = []string{
+	"see_hides", "access_without_password", "offline_download", "write",
+	"rename", "move", "copy", "remove", "webdav_read", "webdav_manage",
+	"ftp_access", "ftp_manage",
+}
+
+func permBit(name string) (int32, error) {
+	for i, n := range permNames {
+		if n == name {
+			return 1 << i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown permission [%s], should be one of %s", name, strings.Join(permNames, ","))
+}
+
+func permString(perm int32) string {
+	var names []string
+	for i, n := range permNames {
+		if perm&(1<<i) != 0 {
+			names = append(names, n)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+var (
+	userPassword string
+	userBasePath string
+	userDisabled bool
+	userPerms    []string
+	userGrants   []string
+	userRevokes  []string
+)
+
+// userCmd represents the user command
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage users",
+}
+
+var createUserCmd = &cobra.Command{
+	Use:   "create USERNAME",
+	Short: "Create a user",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("username is required")
+			return
+		}
+		if userPassword == "" {
+			utils.Log.Errorf("password is required")
+			return
+		}
+		user := &model.User{
+			Username: args[0],
+			BasePath: userBasePath,
+			Role:     model.GENERAL,
+			Disabled: userDisabled,
+		}
+		for _, name := range userPerms {
+			bit, err := permBit(name)
+			if err != nil {
+				utils.Log.Errorf("%s", err)
+				return
+			}
+			user.Permission |= bit
+		}
+		user.SetPassword(userPassword)
+		Init()
+		defer Release()
+		if err := op.CreateUser(user); err != nil {
+			utils.Log.Errorf("failed to create user: %+v", err)
+			return
+		}
+		utils.Log.Infof("user [%s] has been created with permissions [%s]", user.Username, permString(user.Permission))
+	},
+}
+
+var updateUserCmd = &cobra.Command{
+	Use:   "update USERNAME",
+	Short: "Update the password, base path or status of a user",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("username is required")
+			return
+		}
+		Init()
+		defer Release()
+		user, err := op.GetUserByName(args[0])
+		if err != nil {
+			utils.Log.Errorf("failed to get user: %+v", err)
+			return
+		}
+		if cmd.Flags().Changed("password") {
+			user.SetPassword(userPassword)
+		}
+		if cmd.Flags().Changed("base-path") {
+			user.BasePath = userBasePath
+		}
+		if cmd.Flags().Changed("disabled") {
+			user.Disabled = userDisabled
+		}
+		if err = op.UpdateUser(user); err != nil {
+			utils.Log.Errorf("failed to update user: %+v", err)
+			return
+		}
+		utils.Log.Infof("user [%s] has been updated", user.Username)
+		DelUserCacheOnline(user.Username)
+	},
+}
+
+var permUserCmd = &cobra.Command{
+	Use:   "perm USERNAME",
+	Short: "Show or change the permissions of a user",
+	Long: `Show or change the permissions of a user, the permissions are
+` + strings.Join(permNames, ", "),
+	Example: "  alist user perm bob --grant write,rename --revoke remove",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("username is required")
+			return
+		}
+		Init()
+		defer Release()
+		user, err := op.GetUserByName(args[0])
+		if err != nil {
+			utils.Log.Errorf("failed to get user: %+v", err)
+			return
+		}
+		if len(userGrants) == 0 && len(userRevokes) == 0 {
+			utils.Log.Infof("permissions of [%s]: %s", user.Username, permString(user.Permission))
+			return
+		}
+		for _, name := range userGrants {
+			bit, err := permBit(name)
+			if err != nil {
+				utils.Log.Errorf("%s", err)
+				return
+			}
+			user.Permission |= bit
+		}
+		for _, name := range userRevokes {
+			bit, err := permBit(name)
+			if err != nil {
+				utils.Log.Errorf("%s", err)
+				return
+			}
+			user.Permission &^= bit
+		}
+		if err = op.UpdateUser(user); err != nil {
+			utils.Log.Errorf("failed to update user: %+v", err)
+			return
+		}
+		utils.Log.Infof("permissions of [%s]: %s", user.Username, permString(user.Permission))
+		DelUserCacheOnline(user.Username)
+	},
+}
+
 func DelAdminCacheOnline() {
 	admin, err := op.GetAdmin()
 	if err != nil {
@@ -50,3 +211,18 @@ func DelUserCacheOnline(username string) {
 	}
 	utils.Log.Debugf("[del_user_cache_online] del user [%s] cache success", username)
 }
+
+func init() {
+	RootCmd.AddCommand(userCmd)
+	userCmd.AddCommand(createUserCmd)
+	userCmd.AddCommand(updateUserCmd)
+	userCmd.AddCommand(permUserCmd)
+	for _, c := range []*cobra.Command{createUserCmd, updateUserCmd} {
+		c.Flags().StringVarP(&userPassword, "password", "p", "", "password of the user")
+		c.Flags().StringVar(&userBasePath, "base-path", "/", "base path of the user")
+		c.Flags().BoolVar(&userDisabled, "disabled", false, "disable the user")
+	}
+	createUserCmd.Flags().StringSliceVar(&userPerms, "perm", nil, "permissions, comma separated")
+	permUserCmd.Flags().StringSliceVar(&userGrants, "grant", nil, "permissions to grant, comma separated")
+	permUserCmd.Flags().StringSliceVar(&userRevokes, "revoke", nil, "permissions to revoke, comma separated")
+}
@@ -16,6 +16,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:        "115 Cloud",
+	NeedStore:   true,
 	DefaultRoot: "0",
 	// OnlyProxy:   true,
 	// OnlyLocal:         true,
@@ -16,6 +16,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:        "123Pan",
+	NeedStore:   true,
 	DefaultRoot: "0",
 	LocalSort:   true,
 }
@@ -16,6 +16,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:             "139Yun",
+	NeedStore:        true,
 	LocalSort:        true,
 	ProxyRangeOption: true,
 }
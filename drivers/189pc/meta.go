@@ -23,6 +23,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:        "189CloudPC",
+	NeedStore:   true,
 	DefaultRoot: "-11",
 	CheckStatus: true,
 }
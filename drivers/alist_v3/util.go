@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
@@ -55,6 +56,9 @@ func (d *AListV3) request(api, method string, callback base.ReqCallback, retry .
 			}
 			return d.request(api, method, callback, true)
 		}
+		if code == 401 || code == 403 {
+			return nil, errs.NewErr(errs.Unauthorized, "code: %d, message: %s", code, utils.Json.Get(res.Body(), "message").ToString())
+		}
 		return nil, fmt.Errorf("request failed,code: %d, message: %s", code, utils.Json.Get(res.Body(), "message").ToString())
 	}
 	return res.Body(), nil
@@ -23,6 +23,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:              "AliyundriveOpen",
+	NeedStore:         true,
 	LocalSort:         false,
 	OnlyLocal:         false,
 	OnlyProxy:         false,
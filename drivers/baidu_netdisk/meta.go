@@ -22,6 +22,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:        "BaiduNetdisk",
+	NeedStore:   true,
 	DefaultRoot: "/",
 }
 
@@ -19,6 +19,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:      "BaiduPhoto",
+	NeedStore: true,
 	LocalSort: true,
 }
 
@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"context"
+	"io"
+	stdpath "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/cmd/flags"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+type Cache struct {
+	model.Storage
+	Addition
+	store *store
+	// ctx is canceled on drop to stop the prefetch and cleaning in wg
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (d *Cache) Config() driver.Config {
+	return config
+}
+
+func (d *Cache) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Cache) Init(ctx context.Context) error {
+	d.RemotePath = utils.FixAndCleanPath(d.RemotePath)
+	if utils.IsSubPath(d.MountPath, d.RemotePath) || utils.IsSubPath(d.RemotePath, d.MountPath) {
+		return errors.New("remote path can't contain the cache itself")
+	}
+	if d.CacheDir == "" {
+		d.CacheDir = filepath.Join(flags.DataDir, "cache", strconv.Itoa(int(d.ID)))
+	}
+	var pinned []string
+	for _, path := range strings.Split(d.PinnedPaths, "\n") {
+		if path = strings.TrimSpace(path); path != "" {
+			pinned = append(pinned, utils.FixAndCleanPath(path))
+		}
+	}
+	var err error
+	d.store, err = newStore(d.CacheDir, int64(d.MaxSize)*1024*1024,
+		time.Duration(d.FileTTL)*time.Hour, time.Duration(d.ListTTL)*time.Minute, pinned)
+	if err != nil {
+		return err
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	d.background(d.cleanLists)
+	for _, path := range pinned {
+		d.background(func(ctx context.Context) {
+			d.prefetch(ctx, path)
+		})
+	}
+	return nil
+}
+
+func (d *Cache) Drop(ctx context.Context) error {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+	if d.store != nil {
+		d.store.close()
+	}
+	return nil
+}
+
+func (d *Cache) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	dir, name := stdpath.Split(path)
+	objs, err := d.list(ctx, utils.FixAndCleanPath(dir), false)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		if obj.GetName() == name {
+			return obj, nil
+		}
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (d *Cache) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	return d.list(ctx, dir.GetPath(), args.Refresh)
+}
+
+func (d *Cache) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	remote := d.remote(file.GetPath(), args)
+	rangeReader := func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+		e := d.store.acquire(file.GetPath(), file.GetSize(), file.ModTime())
+		rc, err := d.store.newReader(ctx, e, r, remote)
+		if err != nil {
+			d.store.release(e)
+			return nil, err
+		}
+		return rc, nil
+	}
+	return &model.Link{
+		RangeReadCloser: &model.RangeReadCloser{RangeReader: rangeReader},
+	}, nil
+}
+
+func (d *Cache) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	defer d.invalidate(stdpath.Join(parentDir.GetPath(), dirName))
+	storage, actualPath, err := d.remoteStorage(stdpath.Join(parentDir.GetPath(), dirName))
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, actualPath)
+}
+
+func (d *Cache) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	defer d.invalidate(srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+	srcStorage, srcActualPath, err := d.remoteStorage(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	dstStorage, dstActualPath, err := d.remoteStorage(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	if srcStorage.GetStorage() != dstStorage.GetStorage() {
+		return errs.MoveBetweenTwoStorages
+	}
+	return op.Move(ctx, srcStorage, srcActualPath, dstActualPath)
+}
+
+func (d *Cache) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	defer d.invalidate(srcObj.GetPath(), stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName))
+	storage, actualPath, err := d.remoteStorage(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Rename(ctx, storage, actualPath, newName)
+}
+
+func (d *Cache) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	defer d.invalidate(stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+	srcStorage, srcActualPath, err := d.remoteStorage(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	dstStorage, dstActualPath, err := d.remoteStorage(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+		return op.Copy(ctx, srcStorage, srcActualPath, dstActualPath)
+	}
+	return fs.CopyDirectly(ctx, srcStorage, srcActualPath, dstStorage, dstActualPath)
+}
+
+func (d *Cache) Remove(ctx context.Context, obj model.Obj) error {
+	defer d.invalidate(obj.GetPath())
+	storage, actualPath, err := d.remoteStorage(obj.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Remove(ctx, storage, actualPath)
+}
+
+func (d *Cache) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	defer d.invalidate(stdpath.Join(dstDir.GetPath(), stream.GetName()))
+	storage, actualPath, err := d.remoteStorage(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Put(ctx, storage, actualPath, stream, up)
+}
+
+func (d *Cache) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	path := args.Obj.GetPath()
+	switch args.Method {
+	case "pin":
+		if d.store.pin(path) {
+			d.savePinned()
+		}
+		d.background(func(ctx context.Context) {
+			d.prefetch(ctx, path)
+		})
+		return d.store.pinnedPaths(), nil
+	case "unpin":
+		d.store.unpin(path)
+		d.savePinned()
+		return d.store.pinnedPaths(), nil
+	case "stats":
+		return d.store.stats(), nil
+	case "clear":
+		return nil, d.store.clear()
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
+func (d *Cache) IsWriteMethod(method string) bool {
+	return method == "pin" || method == "unpin" || method == "clear"
+}
+
+var _ driver.Driver = (*Cache)(nil)
+var _ driver.WriteOther = (*Cache)(nil)
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	RemotePath  string `json:"remote_path" required:"true" help:"the alist path to cache"`
+	CacheDir    string `json:"cache_dir" help:"local directory to store the cache, default to data/cache/<storage id>"`
+	MaxSize     int    `json:"max_size" type:"number" default:"10240" help:"MB, least recently used files are evicted beyond it, pinned files are never evicted"`
+	ListTTL     int    `json:"list_ttl" type:"number" default:"30" help:"minutes, stale lists are still served when the remote is unavailable"`
+	FileTTL     int    `json:"file_ttl" type:"number" default:"72" help:"hours, 0 means never expire"`
+	PinnedPaths string `json:"pinned_paths" type:"text" help:"one path per line, files under them are fetched in advance and kept for offline access"`
+}
+
+var config = driver.Config{
+	Name:        "Cache",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Cache{}
+	})
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/pkg/errors"
+)
+
+// at most so many missing blocks are fetched from the remote at a time
+const maxFetchBlocks = 8
+
+type remoteReader func(ctx context.Context, start, length int64) (io.ReadCloser, error)
+
+// blockReader reads a range of the file, missing blocks are fetched from the remote and cached
+type blockReader struct {
+	ctx    context.Context
+	s      *store
+	e      *fileEntry
+	file   *os.File
+	remote remoteReader
+	offset int64
+	end    int64
+}
+
+func (s *store) newReader(ctx context.Context, e *fileEntry, r http_range.Range, remote remoteReader) (*blockReader, error) {
+	file, err := os.OpenFile(s.dataPath(e), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	end := e.Size
+	if r.Length >= 0 && r.Start+r.Length < end {
+		end = r.Start + r.Length
+	}
+	return &blockReader{ctx: ctx, s: s, e: e, file: file, remote: remote, offset: r.Start, end: end}, nil
+}
+
+func (r *blockReader) Read(p []byte) (int, error) {
+	if r.offset >= r.end {
+		return 0, io.EOF
+	}
+	block := r.offset / blockSize
+	if !r.s.hasBlock(r.e, block) {
+		if err := r.fetch(block); err != nil {
+			return 0, err
+		}
+	}
+	n := min(int64(len(p)), (block+1)*blockSize-r.offset, r.end-r.offset)
+	n2, err := r.file.ReadAt(p[:n], r.offset)
+	r.offset += int64(n2)
+	if err == io.EOF && n2 > 0 {
+		err = nil
+	}
+	return n2, err
+}
+
+// fetch downloads the run of missing blocks starting at block into the data file
+func (r *blockReader) fetch(block int64) error {
+	last := block + 1
+	for last-block < maxFetchBlocks && last*blockSize < r.end && !r.s.hasBlock(r.e, last) {
+		last++
+	}
+	start := block * blockSize
+	length := min(last*blockSize, r.e.Size) - start
+	rc, err := r.remote(r.ctx, start, length)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	n, err := io.Copy(io.NewOffsetWriter(r.file, start), io.LimitReader(rc, length))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if n != length {
+		return errors.Errorf("remote returned %d bytes, expect %d", n, length)
+	}
+	r.s.markBlocks(r.e, block, last)
+	return nil
+}
+
+func (r *blockReader) Close() error {
+	err := r.file.Close()
+	r.s.release(r.e)
+	return err
+}
@@ -0,0 +1,369 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	blockSize = 1024 * 1024
+	// the index is written at most once in saveDelay
+	saveDelay = 10 * time.Second
+	// expired lists are kept for so long to be served when the remote fails
+	listKeep = 24 * time.Hour
+)
+
+// store keeps the cached lists and file blocks under dir
+type store struct {
+	dir     string
+	maxSize int64
+	fileTTL time.Duration
+	listTTL time.Duration
+
+	mu     sync.Mutex
+	files  map[string]*fileEntry
+	used   int64
+	pinned []string
+	timer  *time.Timer
+	closed bool
+}
+
+func newStore(dir string, maxSize int64, fileTTL, listTTL time.Duration, pinned []string) (*store, error) {
+	s := &store{
+		dir:     dir,
+		maxSize: maxSize,
+		fileTTL: fileTTL,
+		listTTL: listTTL,
+		pinned:  pinned,
+		files:   make(map[string]*fileEntry),
+	}
+	for _, sub := range []string{"data", "lists"} {
+		if err := utils.CreateNestedDirectory(filepath.Join(dir, sub)); err != nil {
+			return nil, err
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	if err = utils.Json.Unmarshal(data, &s.files); err != nil {
+		log.Warnf("[cache] broken index in %s, the cache is dropped: %s", dir, err)
+		s.files = make(map[string]*fileEntry)
+		return s, nil
+	}
+	for _, e := range s.files {
+		s.used += e.Cached
+	}
+	return s, nil
+}
+
+// dataPath is unique per entry, a changed file is cached anew while the old data is still read
+func (s *store) dataPath(e *fileEntry) string {
+	return filepath.Join(s.dir, "data", fmt.Sprintf("%s-%d", utils.GetMD5EncodeStr(e.Path), e.CreatedAt.UnixNano()))
+}
+
+// listDir returns the dir holding the lists of path and of everything under it,
+// it nests like the path with every name hashed
+func (s *store) listDir(path string) string {
+	parts := []string{s.dir, "lists"}
+	for _, name := range strings.Split(utils.FixAndCleanPath(path), "/") {
+		if name != "" {
+			parts = append(parts, utils.GetMD5EncodeStr(name))
+		}
+	}
+	return filepath.Join(parts...)
+}
+
+func (s *store) listPath(path string) string {
+	return filepath.Join(s.listDir(path), "list.json")
+}
+
+// save writes the index, must be called with mu held
+func (s *store) save() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.closed {
+		return
+	}
+	data, err := utils.Json.Marshal(s.files)
+	if err != nil {
+		log.Errorf("[cache] failed marshal index: %s", err)
+		return
+	}
+	if err = os.WriteFile(filepath.Join(s.dir, "index.json"), data, 0644); err != nil {
+		log.Errorf("[cache] failed save index: %s", err)
+	}
+}
+
+// saveLater writes the index after saveDelay, must be called with mu held
+func (s *store) saveLater() {
+	if s.timer != nil || s.closed {
+		return
+	}
+	s.timer = time.AfterFunc(saveDelay, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.timer = nil
+		s.save()
+	})
+}
+
+// close writes the pending index, the store can't be saved any more after it
+func (s *store) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.save()
+	}
+	s.closed = true
+}
+
+// isPinned must be called with mu held
+func (s *store) isPinned(path string) bool {
+	for _, p := range s.pinned {
+		if utils.IsSubPath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// pin adds path to the pinned paths, reports whether it wasn't pinned yet
+func (s *store) pin(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isPinned(path) {
+		return false
+	}
+	s.pinned = append(s.pinned, path)
+	return true
+}
+
+func (s *store) unpin(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pinned = utils.SliceFilter(s.pinned, func(p string) bool {
+		return p != path
+	})
+}
+
+func (s *store) pinnedPaths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.pinned...)
+}
+
+// acquire returns the entry of the file and holds it from eviction until release,
+// the cached data is dropped if the remote file changed or it expired
+func (s *store) acquire(path string, size int64, modified time.Time) *fileEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.files[path]
+	if ok && (e.Size != size || !e.Modified.Equal(modified) ||
+		(s.fileTTL > 0 && time.Since(e.CreatedAt) > s.fileTTL && !s.isPinned(path))) {
+		s.detach(e)
+		ok = false
+	}
+	if !ok {
+		e = &fileEntry{
+			Path:      path,
+			Size:      size,
+			Modified:  modified,
+			Blocks:    make([]byte, (size+blockSize*8-1)/(blockSize*8)),
+			CreatedAt: time.Now(),
+		}
+		s.files[path] = e
+	}
+	e.refs++
+	e.LastAccess = time.Now()
+	return e
+}
+
+func (s *store) release(e *fileEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.refs--
+	if e.refs == 0 && s.files[e.Path] != e {
+		s.drop(e)
+	}
+	s.evict()
+	s.saveLater()
+}
+
+func (s *store) hasBlock(e *fileEntry, i int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return e.hasBlock(i)
+}
+
+// markBlocks marks the blocks in [from, to) as cached
+func (s *store) markBlocks(e *fileEntry, from, to int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := from; i < to; i++ {
+		if e.hasBlock(i) {
+			continue
+		}
+		e.Blocks[i/8] |= 1 << (i % 8)
+		n := min(blockSize, e.Size-i*blockSize)
+		e.Cached += n
+		s.used += n
+	}
+}
+
+// drop removes the entry and its data, must be called with mu held
+func (s *store) drop(e *fileEntry) {
+	if err := os.Remove(s.dataPath(e)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("[cache] failed remove data of %s: %s", e.Path, err)
+	}
+	s.used -= e.Cached
+	if s.files[e.Path] == e {
+		delete(s.files, e.Path)
+	}
+}
+
+// detach drops the entry, or only removes it from the index if it's being read,
+// then it's dropped on the last release. Must be called with mu held
+func (s *store) detach(e *fileEntry) {
+	if e.refs == 0 {
+		s.drop(e)
+	} else {
+		delete(s.files, e.Path)
+	}
+}
+
+// evict drops the least recently used files until the cache fits, must be called with mu held
+func (s *store) evict() {
+	if s.maxSize <= 0 || s.used <= s.maxSize {
+		return
+	}
+	entries := make([]*fileEntry, 0, len(s.files))
+	for _, e := range s.files {
+		if e.refs == 0 && !s.isPinned(e.Path) {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastAccess.Before(entries[j].LastAccess)
+	})
+	for _, e := range entries {
+		if s.used <= s.maxSize {
+			break
+		}
+		s.drop(e)
+	}
+}
+
+// invalidate drops the cached lists and files of path and everything under it
+func (s *store) invalidate(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for p, e := range s.files {
+		if utils.IsSubPath(path, p) {
+			s.detach(e)
+		}
+	}
+	s.save()
+	if err := os.RemoveAll(s.listDir(path)); err != nil {
+		log.Warnf("[cache] failed remove lists of %s: %s", path, err)
+	}
+}
+
+// invalidateList drops the cached list of path only
+func (s *store) invalidateList(path string) {
+	if err := os.Remove(s.listPath(path)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("[cache] failed remove list of %s: %s", path, err)
+	}
+}
+
+// cleanLists removes the lists not refreshed for listTTL and listKeep
+func (s *store) cleanLists() {
+	err := filepath.WalkDir(filepath.Join(s.dir, "lists"), func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) <= s.listTTL+listKeep {
+			return nil
+		}
+		if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Warnf("[cache] failed remove list %s: %s", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warnf("[cache] failed walk lists: %s", err)
+	}
+}
+
+func (s *store) getList(path string) (*listEntry, bool) {
+	data, err := os.ReadFile(s.listPath(path))
+	if err != nil {
+		return nil, false
+	}
+	var l listEntry
+	if err = utils.Json.Unmarshal(data, &l); err != nil {
+		return nil, false
+	}
+	return &l, true
+}
+
+func (s *store) setList(path string, l *listEntry) {
+	data, err := utils.Json.Marshal(l)
+	if err != nil {
+		return
+	}
+	if err = utils.CreateNestedDirectory(s.listDir(path)); err == nil {
+		err = os.WriteFile(s.listPath(path), data, 0644)
+	}
+	if err != nil {
+		log.Warnf("[cache] failed save list of %s: %s", path, err)
+	}
+}
+
+type Stats struct {
+	Files     int   `json:"files"`
+	Complete  int   `json:"complete"`
+	UsedSpace int64 `json:"used_space"`
+	MaxSize   int64 `json:"max_size"`
+}
+
+func (s *store) stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := Stats{Files: len(s.files), UsedSpace: s.used, MaxSize: s.maxSize}
+	for _, e := range s.files {
+		if e.complete() {
+			res.Complete++
+		}
+	}
+	return res
+}
+
+// clear drops all cached files and lists
+func (s *store) clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.files {
+		s.detach(e)
+	}
+	s.save()
+	if err := os.RemoveAll(filepath.Join(s.dir, "lists")); err != nil {
+		return errors.WithStack(err)
+	}
+	return utils.CreateNestedDirectory(filepath.Join(s.dir, "lists"))
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/http_range"
+)
+
+func TestBlockReader(t *testing.T) {
+	s, err := newStore(t.TempDir(), blockSize*2, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, blockSize*2+100)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	fetched := 0
+	remote := func(ctx context.Context, start, length int64) (io.ReadCloser, error) {
+		fetched += int(length)
+		return io.NopCloser(bytes.NewReader(data[start : start+length])), nil
+	}
+	read := func(path string, r http_range.Range) []byte {
+		e := s.acquire(path, int64(len(data)), time.Unix(0, 0))
+		rc, err := s.newReader(context.Background(), e, r, remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		res, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	if got := read("/a", http_range.Range{Start: blockSize + 10, Length: 20}); !bytes.Equal(got, data[blockSize+10:blockSize+30]) {
+		t.Fatal("ranged read mismatch")
+	}
+	if fetched != blockSize {
+		t.Errorf("fetched %d bytes, expect only the missing blocks", fetched)
+	}
+	if got := read("/a", http_range.Range{Length: -1}); !bytes.Equal(got, data) {
+		t.Fatal("full read mismatch")
+	}
+	if fetched != len(data) {
+		t.Errorf("cached blocks should not be fetched again, fetched %d", fetched)
+	}
+
+	read("/b", http_range.Range{Length: -1})
+	if _, ok := s.files["/a"]; ok {
+		t.Error("least recently used file should be evicted")
+	}
+	if s.used > s.maxSize {
+		t.Errorf("used %d exceeds max size %d", s.used, s.maxSize)
+	}
+}
+
+func TestStoreSave(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newStore(dir, 0, 0, time.Minute, []string{"/pinned"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := s.acquire("/pinned/a", 10, time.Unix(0, 0))
+	s.markBlocks(e, 0, 1)
+	s.release(e)
+	if _, err = os.Stat(filepath.Join(dir, "index.json")); !os.IsNotExist(err) {
+		t.Error("index should not be written on every release")
+	}
+	s.close()
+	s, err = newStore(dir, 0, 0, time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := s.files["/pinned/a"]; !ok || !e.complete() {
+		t.Error("index should be flushed on close")
+	}
+
+	s.setList("/old", &listEntry{})
+	s.setList("/new", &listEntry{})
+	old := time.Now().Add(-listKeep - 2*time.Minute)
+	if err = os.Chtimes(s.listPath("/old"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	s.cleanLists()
+	if _, ok := s.getList("/old"); ok {
+		t.Error("long expired list should be removed")
+	}
+	if _, ok := s.getList("/new"); !ok {
+		t.Error("fresh list should be kept")
+	}
+}
+
+func TestStoreInvalidate(t *testing.T) {
+	s, err := newStore(t.TempDir(), 0, 0, time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/a", "/a/b", "/a/b/c", "/ab"} {
+		s.setList(path, &listEntry{})
+	}
+	s.invalidate("/a/b")
+	for path, want := range map[string]bool{"/a": true, "/a/b": false, "/a/b/c": false, "/ab": true} {
+		if _, ok := s.getList(path); ok != want {
+			t.Errorf("list of %s cached: %v, expect %v", path, ok, want)
+		}
+	}
+
+	e := s.acquire("/f", 10, time.Unix(0, 0))
+	if err = os.WriteFile(s.dataPath(e), make([]byte, 10), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s.markBlocks(e, 0, 1)
+	changed := s.acquire("/f", 20, time.Unix(1, 0))
+	if changed == e || changed.Cached != 0 {
+		t.Fatal("a changed file should get a new entry while the old one is read")
+	}
+	s.release(e)
+	if _, err = os.Stat(s.dataPath(e)); !os.IsNotExist(err) {
+		t.Error("data of the replaced entry should be removed on release")
+	}
+	if s.used != 0 {
+		t.Errorf("used %d, expect 0", s.used)
+	}
+	s.release(changed)
+}
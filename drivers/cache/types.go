@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type cachedObj struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	IsFolder bool      `json:"is_folder"`
+	Hash     string    `json:"hash"`
+	Thumb    string    `json:"thumb"`
+}
+
+type listEntry struct {
+	Objs      []cachedObj `json:"objs"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+func fromObj(obj model.Obj) cachedObj {
+	thumb, _ := model.GetThumb(obj)
+	return cachedObj{
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+		Hash:     obj.GetHash().String(),
+		Thumb:    thumb,
+	}
+}
+
+func (o cachedObj) toObj(dir string) model.Obj {
+	obj := model.Object{
+		Path:     dir + "/" + o.Name,
+		Name:     o.Name,
+		Size:     o.Size,
+		Modified: o.Modified,
+		IsFolder: o.IsFolder,
+	}
+	if o.Hash != "" {
+		obj.HashInfo = utils.FromString(o.Hash)
+	}
+	if dir == "/" {
+		obj.Path = "/" + o.Name
+	}
+	if o.Thumb == "" {
+		return &obj
+	}
+	return &model.ObjThumb{
+		Object:    obj,
+		Thumbnail: model.Thumbnail{Thumbnail: o.Thumb},
+	}
+}
+
+// fileEntry records which blocks of a remote file are cached locally
+type fileEntry struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	Modified   time.Time `json:"modified"`
+	Blocks     []byte    `json:"blocks"` // bitset of cached blocks
+	Cached     int64     `json:"cached"` // cached bytes
+	CreatedAt  time.Time `json:"created_at"`
+	LastAccess time.Time `json:"last_access"`
+
+	refs int
+}
+
+func (e *fileEntry) hasBlock(i int64) bool {
+	return int(i/8) < len(e.Blocks) && e.Blocks[i/8]&(1<<(i%8)) != 0
+}
+
+func (e *fileEntry) complete() bool {
+	return e.Cached >= e.Size
+}
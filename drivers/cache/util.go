@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"io"
+	stdpath "path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	log "github.com/sirupsen/logrus"
+)
+
+func (d *Cache) remotePath(path string) string {
+	return stdpath.Join(d.RemotePath, path)
+}
+
+// remoteStorage resolves path to the storage behind it, the writes go through op,
+// so the hooks of fs are run only once by the write on this storage
+func (d *Cache) remoteStorage(path string) (driver.Driver, string, error) {
+	return op.GetStorageAndActualPath(d.remotePath(path))
+}
+
+func (d *Cache) savePinned() {
+	d.PinnedPaths = strings.Join(d.store.pinnedPaths(), "\n")
+	op.MustSaveDriverStorage(d)
+}
+
+// background runs f until the driver is dropped
+func (d *Cache) background(f func(ctx context.Context)) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		f(d.ctx)
+	}()
+}
+
+// cleanLists removes the long expired lists every hour
+func (d *Cache) cleanLists(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		d.store.cleanLists()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// invalidate drops the cache of the paths and the lists of their parents
+func (d *Cache) invalidate(paths ...string) {
+	for _, path := range paths {
+		d.store.invalidate(path)
+		d.store.invalidateList(stdpath.Dir(path))
+	}
+}
+
+// list serves the cached list if it's fresh, the stale one is served if the remote fails
+func (d *Cache) list(ctx context.Context, path string, refresh bool) ([]model.Obj, error) {
+	l, ok := d.store.getList(path)
+	if !ok || refresh || time.Now().After(l.ExpiresAt) {
+		objs, err := fs.List(ctx, d.remotePath(path), &fs.ListArgs{NoLog: true, Refresh: refresh})
+		if err != nil {
+			if !ok || errs.IsObjectNotFound(err) {
+				return nil, err
+			}
+			log.Warnf("[cache] serve stale list of %s: %s", path, err)
+		} else {
+			l = &listEntry{
+				Objs:      make([]cachedObj, len(objs)),
+				ExpiresAt: time.Now().Add(time.Duration(d.ListTTL) * time.Minute),
+			}
+			for i, obj := range objs {
+				l.Objs[i] = fromObj(obj)
+			}
+			d.store.setList(path, l)
+		}
+	}
+	res := make([]model.Obj, len(l.Objs))
+	for i, o := range l.Objs {
+		res[i] = o.toObj(path)
+	}
+	return res, nil
+}
+
+// remote returns a reader of the remote file, the link is only requested on the first read
+func (d *Cache) remote(path string, args model.LinkArgs) remoteReader {
+	var (
+		once sync.Once
+		rrc  model.RangeReadCloserIF
+		err  error
+	)
+	return func(ctx context.Context, start, length int64) (io.ReadCloser, error) {
+		once.Do(func() {
+			var link *model.Link
+			var file model.Obj
+			link, file, err = fs.Link(ctx, d.remotePath(path), args)
+			if err != nil {
+				return
+			}
+			switch {
+			case link.RangeReadCloser != nil:
+				rrc = link.RangeReadCloser
+			case link.URL != "":
+				rrc, err = stream.GetRangeReadCloserFromLink(file.GetSize(), link)
+			case link.MFile != nil:
+				rrc = &model.RangeReadCloser{RangeReader: func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+					return io.NopCloser(io.NewSectionReader(link.MFile, r.Start, r.Length)), nil
+				}}
+			default:
+				err = errs.NotSupport
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		return rrc.RangeRead(ctx, http_range.Range{Start: start, Length: length})
+	}
+}
+
+// prefetch caches all files under path
+func (d *Cache) prefetch(ctx context.Context, path string) {
+	obj, err := d.Get(ctx, path)
+	if err != nil {
+		log.Warnf("[cache] failed prefetch %s: %s", path, err)
+		return
+	}
+	if err = d.fetch(ctx, obj); err != nil {
+		log.Warnf("[cache] failed prefetch %s: %+v", path, err)
+	}
+}
+
+func (d *Cache) fetch(ctx context.Context, obj model.Obj) error {
+	if !obj.IsDir() {
+		e := d.store.acquire(obj.GetPath(), obj.GetSize(), obj.ModTime())
+		rc, err := d.store.newReader(ctx, e, http_range.Range{Length: -1}, d.remote(obj.GetPath(), model.LinkArgs{}))
+		if err != nil {
+			d.store.release(e)
+			return err
+		}
+		defer rc.Close()
+		_, err = io.Copy(io.Discard, rc)
+		return err
+	}
+	objs, err := d.list(ctx, obj.GetPath(), false)
+	if err != nil {
+		return err
+	}
+	for _, o := range objs {
+		if err = d.fetch(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ func init() {
 		return &ILanZou{
 			config: driver.Config{
 				Name:              "ILanZou",
+				NeedStore:         true,
 				LocalSort:         false,
 				OnlyLocal:         false,
 				OnlyProxy:         false,
@@ -55,6 +56,7 @@ func init() {
 		return &ILanZou{
 			config: driver.Config{
 				Name:              "FeijiPan",
+				NeedStore:         true,
 				LocalSort:         false,
 				OnlyLocal:         false,
 				OnlyProxy:         false,
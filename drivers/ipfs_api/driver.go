@@ -8,16 +8,20 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	shell "github.com/ipfs/go-ipfs-api"
 )
 
 type IPFS struct {
 	model.Storage
 	Addition
-	sh      *shell.Shell
-	gateURL *url.URL
+	sh        *shell.Shell
+	gateURL   *url.URL
+	publicURL *url.URL
 }
 
 func (d *IPFS) Config() driver.Config {
@@ -35,6 +39,11 @@ func (d *IPFS) Init(ctx context.Context) error {
 		return err
 	}
 	d.gateURL = gateURL
+	if d.LinkMode == "public" {
+		if d.publicURL, err = url.Parse(d.PublicGateway); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -60,21 +69,17 @@ func (d *IPFS) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]
 
 	objlist := []model.Obj{}
 	for _, file := range dirs {
-		gateurl := *d.gateURL
-		gateurl.Path = "ipfs/" + file.Hash
-		gateurl.RawQuery = "filename=" + url.PathEscape(file.Name)
-		objlist = append(objlist, &model.ObjectURL{
+		objlist = append(objlist, &Object{ObjectURL: model.ObjectURL{
 			Object: model.Object{ID: file.Hash, Name: file.Name, Size: int64(file.Size), IsFolder: file.Type == 1},
-			Url:    model.Url{Url: gateurl.String()},
-		})
+			Url:    model.Url{Url: d.link(file.Hash, file.Name)},
+		}})
 	}
 
 	return objlist, nil
 }
 
 func (d *IPFS) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	link := d.Gateway + "/ipfs/" + file.GetID() + "/?filename=" + url.PathEscape(file.GetName())
-	return &model.Link{URL: link}, nil
+	return &model.Link{URL: d.link(file.GetID(), file.GetName())}, nil
 }
 
 func (d *IPFS) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
@@ -121,8 +126,39 @@ func ToFiles(dstDir string) shell.AddOpts {
 	}
 }
 
-//func (d *Template) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
-//	return nil, errs.NotSupport
-//}
+func (d *IPFS) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	cid, err := d.cid(ctx, args.Obj)
+	if err != nil {
+		return nil, err
+	}
+	switch args.Method {
+	case "cid":
+		return base.Json{"cid": cid, "url": d.link(cid, args.Obj.GetName())}, nil
+	case "pin":
+		if err = d.sh.Request("pin/add", cid).Option("recursive", true).Exec(ctx, nil); err != nil {
+			return nil, err
+		}
+		return base.Json{"cid": cid, "pinned": true}, nil
+	case "unpin":
+		if err = d.sh.Request("pin/rm", cid).Option("recursive", true).Exec(ctx, nil); err != nil {
+			return nil, err
+		}
+		return base.Json{"cid": cid, "pinned": false}, nil
+	case "pin_status":
+		return d.pinStatus(ctx, cid)
+	case "keys":
+		return d.sh.KeyList(ctx)
+	case "publish":
+		return d.publish(cid, args.Data)
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
+func (d *IPFS) IsWriteMethod(method string) bool {
+	return utils.SliceContains([]string{"pin", "unpin", "publish"}, method)
+}
 
 var _ driver.Driver = (*IPFS)(nil)
+var _ driver.Other = (*IPFS)(nil)
+var _ driver.WriteOther = (*IPFS)(nil)
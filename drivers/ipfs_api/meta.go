@@ -8,8 +8,10 @@ import (
 type Addition struct {
 	// Usually one of two
 	driver.RootPath
-	Endpoint string `json:"endpoint" default:"http://127.0.0.1:5001"`
-	Gateway  string `json:"gateway" default:"https://ipfs.io"`
+	Endpoint      string `json:"endpoint" default:"http://127.0.0.1:5001"`
+	Gateway       string `json:"gateway" default:"https://ipfs.io"`
+	LinkMode      string `json:"link_mode" type:"select" options:"gateway,public" default:"gateway" help:"public returns links of the public gateway"`
+	PublicGateway string `json:"public_gateway" default:"https://dweb.link"`
 }
 
 var config = driver.Config{
@@ -0,0 +1,18 @@
+package ipfs
+
+import "github.com/alist-org/alist/v3/internal/model"
+
+type Object struct {
+	model.ObjectURL
+}
+
+func (o *Object) CID() string {
+	return o.ID
+}
+
+type PublishArgs struct {
+	// Key is the name of the key to publish with, the node's own key if empty
+	Key      string `json:"key"`
+	Lifetime string `json:"lifetime"` // duration the record is valid for, e.g. 24h
+	TTL      string `json:"ttl"`      // duration the record may be cached for
+}
@@ -0,0 +1,97 @@
+package ipfs
+
+import (
+	"context"
+	"net/url"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// link returns the gateway url of the cid, the public gateway is used in public link mode
+func (d *IPFS) link(cid, name string) string {
+	gateURL := *d.gateURL
+	if d.LinkMode == "public" {
+		gateURL = *d.publicURL
+	}
+	gateURL.Path = stdpath.Join("/", gateURL.Path, "ipfs", cid)
+	gateURL.RawQuery = "filename=" + url.PathEscape(name)
+	return gateURL.String()
+}
+
+// cid returns the cid of the obj, objs not from a list such as the root are looked up by path
+func (d *IPFS) cid(ctx context.Context, obj model.Obj) (string, error) {
+	if obj.GetID() != "" {
+		return obj.GetID(), nil
+	}
+	stat, err := d.sh.FilesStat(ctx, obj.GetPath())
+	if err != nil {
+		return "", err
+	}
+	return stat.Hash, nil
+}
+
+func (d *IPFS) pinStatus(ctx context.Context, cid string) (base.Json, error) {
+	var resp struct {
+		Keys map[string]struct {
+			Type string
+		}
+	}
+	err := d.sh.Request("pin/ls", cid).Option("type", "all").Exec(ctx, &resp)
+	if err != nil {
+		if strings.Contains(err.Error(), "not pinned") {
+			return base.Json{"cid": cid, "pinned": false}, nil
+		}
+		return nil, err
+	}
+	for _, info := range resp.Keys {
+		return base.Json{"cid": cid, "pinned": true, "type": info.Type}, nil
+	}
+	return base.Json{"cid": cid, "pinned": false}, nil
+}
+
+// publish publishes the cid to ipns, the name stays the same when a new version of a dir is published
+func (d *IPFS) publish(cid string, data interface{}) (base.Json, error) {
+	args, lifetime, ttl, err := parsePublishArgs(data)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.sh.PublishWithDetails("/ipfs/"+cid, args.Key, lifetime, ttl, false)
+	if err != nil {
+		return nil, err
+	}
+	gateURL := *d.gateURL
+	if d.LinkMode == "public" {
+		gateURL = *d.publicURL
+	}
+	gateURL.Path = stdpath.Join("/", gateURL.Path, "ipns", resp.Name)
+	return base.Json{"name": resp.Name, "value": resp.Value, "url": gateURL.String()}, nil
+}
+
+func parsePublishArgs(data interface{}) (args PublishArgs, lifetime, ttl time.Duration, err error) {
+	if data != nil {
+		b, err := utils.Json.Marshal(data)
+		if err != nil {
+			return args, 0, 0, err
+		}
+		if err = utils.Json.Unmarshal(b, &args); err != nil {
+			return args, 0, 0, errors.Wrap(err, "invalid publish args")
+		}
+	}
+	if args.Lifetime != "" {
+		if lifetime, err = time.ParseDuration(args.Lifetime); err != nil {
+			return args, 0, 0, errors.Wrap(err, "invalid lifetime")
+		}
+	}
+	if args.TTL != "" {
+		if ttl, err = time.ParseDuration(args.TTL); err != nil {
+			return args, 0, 0, errors.Wrap(err, "invalid ttl")
+		}
+	}
+	return args, lifetime, ttl, nil
+}
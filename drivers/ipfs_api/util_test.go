@@ -0,0 +1,56 @@
+package ipfs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLink(t *testing.T) {
+	d := &IPFS{Addition: Addition{
+		Endpoint:      "http://127.0.0.1:5001",
+		Gateway:       "http://127.0.0.1:8080",
+		PublicGateway: "https://ipfs.io/base",
+	}}
+	if err := d.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.link("QmCid", "a b.txt"), "http://127.0.0.1:8080/ipfs/QmCid?filename=a%20b.txt"; got != want {
+		t.Errorf("gateway link %s, want %s", got, want)
+	}
+	d.LinkMode = "public"
+	if err := d.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.link("QmCid", "a.txt"), "https://ipfs.io/base/ipfs/QmCid?filename=a.txt"; got != want {
+		t.Errorf("public link %s, want %s", got, want)
+	}
+}
+
+func TestParsePublishArgs(t *testing.T) {
+	args, lifetime, ttl, err := parsePublishArgs(map[string]interface{}{"key": "site", "lifetime": "24h", "ttl": "1m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Key != "site" || lifetime != 24*time.Hour || ttl != time.Minute {
+		t.Errorf("got %+v %s %s", args, lifetime, ttl)
+	}
+	if _, _, _, err = parsePublishArgs(map[string]interface{}{"lifetime": "forever"}); err == nil {
+		t.Error("invalid lifetime should fail")
+	}
+	if args, _, _, err = parsePublishArgs(nil); err != nil || args.Key != "" {
+		t.Errorf("nil data: %+v %v", args, err)
+	}
+}
+
+func TestIsWriteMethod(t *testing.T) {
+	d := &IPFS{}
+	for method, write := range map[string]bool{
+		"cid": false, "pin_status": false, "keys": false,
+		"pin": true, "unpin": true, "publish": true,
+	} {
+		if d.IsWriteMethod(method) != write {
+			t.Errorf("IsWriteMethod(%s) should be %v", method, write)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/times"
 	cp "github.com/otiai10/copy"
+	"github.com/shirou/gopsutil/v3/disk"
 	log "github.com/sirupsen/logrus"
 	_ "golang.org/x/image/webp"
 )
@@ -108,6 +109,17 @@ func (d *Local) Drop(ctx context.Context) error {
 	return nil
 }
 
+func (d *Local) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	usage, err := disk.UsageWithContext(ctx, d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: int64(usage.Total),
+		FreeSpace:  int64(usage.Free),
+	}, nil
+}
+
 func (d *Local) GetAddition() driver.Additional {
 	return &d.Addition
 }
@@ -14,7 +14,8 @@ type Addition struct {
 }
 
 var config = driver.Config{
-	Name: "MediaTrack",
+	Name:      "MediaTrack",
+	NeedStore: true,
 }
 
 func init() {
@@ -0,0 +1,151 @@
+package mirror
+
+import (
+	"context"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	Missing      = "missing"
+	Extra        = "extra"
+	SizeMismatch = "size_mismatch"
+	HashMismatch = "hash_mismatch"
+)
+
+type Divergence struct {
+	Path   string `json:"path"`
+	Member string `json:"member"`
+	Reason string `json:"reason"`
+}
+
+type Report struct {
+	Path        string       `json:"path"`
+	Reference   string       `json:"reference"`
+	Files       int          `json:"files"`
+	Divergences []Divergence `json:"divergences"`
+}
+
+// tree returns all files under path of the member, keyed by the path relative to the mirror
+func (d *Mirror) tree(ctx context.Context, member, path string) (map[string]model.Obj, error) {
+	root := stdpath.Join(member, path)
+	obj, err := fs.Get(ctx, root, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]model.Obj)
+	err = fs.WalkFS(ctx, -1, root, obj, func(reqPath string, info model.Obj) error {
+		if !info.IsDir() {
+			res[stdpath.Join(path, strings.TrimPrefix(reqPath, root))] = info
+		}
+		return nil
+	})
+	return res, err
+}
+
+// check compares the files under path of every member with the reference one
+func (d *Mirror) check(ctx context.Context, reference, path string) (*Report, error) {
+	report := &Report{Path: path, Reference: reference, Divergences: []Divergence{}}
+	refFiles, err := d.tree(ctx, reference, path)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed walk reference [%s]", reference)
+	}
+	report.Files = len(refFiles)
+	for _, member := range d.members {
+		if member == reference {
+			continue
+		}
+		files, err := d.tree(ctx, member, path)
+		if err != nil {
+			files = map[string]model.Obj{}
+		}
+		for p, ref := range refFiles {
+			obj, ok := files[p]
+			reason := ""
+			switch {
+			case !ok:
+				reason = Missing
+			case obj.GetSize() != ref.GetSize():
+				reason = SizeMismatch
+			case hashMismatch(ref.GetHash(), obj.GetHash()):
+				reason = HashMismatch
+			}
+			if reason != "" {
+				report.Divergences = append(report.Divergences, Divergence{Path: p, Member: member, Reason: reason})
+			}
+		}
+		for p := range files {
+			if _, ok := refFiles[p]; !ok {
+				report.Divergences = append(report.Divergences, Divergence{Path: p, Member: member, Reason: Extra})
+			}
+		}
+	}
+	return report, nil
+}
+
+// hashMismatch reports whether both sides have a hash of the same type and they differ
+func hashMismatch(a, b utils.HashInfo) bool {
+	equal, ok := a.Compare(b)
+	return ok && !equal
+}
+
+// repair copies the divergent files from the reference. Extra files are removed with prune,
+// otherwise they are copied to the members missing them, the reference included
+func (d *Mirror) repair(ctx context.Context, report *Report, prune bool) error {
+	var failures []error
+	for _, div := range report.Divergences {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		var err error
+		switch {
+		case div.Reason != Extra:
+			err = copyFile(ctx, report.Reference, div.Member, div.Path)
+		case prune:
+			var storage driver.Driver
+			var actualPath string
+			storage, actualPath, err = op.GetStorageAndActualPath(stdpath.Join(div.Member, div.Path))
+			if err == nil {
+				err = op.Remove(ctx, storage, actualPath)
+			}
+		default:
+			for _, member := range d.members {
+				if member == div.Member {
+					continue
+				}
+				// the same file may be extra in several members
+				if _, e := fs.Get(ctx, stdpath.Join(member, div.Path), &fs.GetArgs{NoLog: true}); !errs.IsObjectNotFound(e) {
+					continue
+				}
+				if e := copyFile(ctx, div.Member, member, div.Path); e != nil {
+					err = e
+				}
+			}
+		}
+		if err != nil {
+			failures = append(failures, errors.WithMessagef(err, "failed repair [%s] in [%s]", div.Path, div.Member))
+		}
+	}
+	return utils.MergeErrors(failures...)
+}
+
+// copyFile copies the file at path of the mirror from one member to another
+func copyFile(ctx context.Context, from, to, path string) error {
+	dstDir := stdpath.Join(to, stdpath.Dir(path))
+	storage, actualPath, err := op.GetStorageAndActualPath(dstDir)
+	if err != nil {
+		return err
+	}
+	if err = op.MakeDir(ctx, storage, actualPath); err != nil {
+		return err
+	}
+	return copyTo(ctx, stdpath.Join(from, path), dstDir)
+}
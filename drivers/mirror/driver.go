@@ -0,0 +1,241 @@
+package mirror
+
+import (
+	"context"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+type Mirror struct {
+	model.Storage
+	Addition
+	members []string
+	queue   chan replication
+	cancel  context.CancelFunc
+	// dropped maps the paths whose replication was dropped to the member having the change
+	dropped generic_sync.MapOf[string, string]
+}
+
+func (d *Mirror) Config() driver.Config {
+	return config
+}
+
+func (d *Mirror) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Mirror) Init(ctx context.Context) error {
+	d.members = nil
+	for _, path := range strings.Split(d.Paths, "\n") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		path = utils.FixAndCleanPath(path)
+		if utils.IsSubPath(d.MountPath, path) || utils.IsSubPath(path, d.MountPath) {
+			return errors.New("paths can't contain the mirror itself")
+		}
+		d.members = append(d.members, path)
+	}
+	if len(d.members) < 2 {
+		return errors.New("at least two paths are required")
+	}
+	if d.Async && d.queue == nil {
+		var replCtx context.Context
+		replCtx, d.cancel = context.WithCancel(context.Background())
+		d.queue = make(chan replication, queueSize)
+		go d.replicate(replCtx, d.queue)
+	}
+	return nil
+}
+
+func (d *Mirror) Drop(ctx context.Context) error {
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+		d.queue = nil
+	}
+	return nil
+}
+
+func (d *Mirror) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	var errs []error
+	for _, member := range d.readMembers() {
+		obj, err := fs.Get(ctx, stdpath.Join(member, path), &fs.GetArgs{NoLog: true})
+		if err == nil {
+			return &model.Object{
+				Path:     path,
+				Name:     obj.GetName(),
+				Size:     obj.GetSize(),
+				Modified: obj.ModTime(),
+				IsFolder: obj.IsDir(),
+				HashInfo: obj.GetHash(),
+			}, nil
+		}
+		errs = append(errs, errors.WithMessagef(err, "failed in member [%s]", member))
+	}
+	return nil, utils.MergeErrors(errs...)
+}
+
+func (d *Mirror) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var errs []error
+	for _, member := range d.readMembers() {
+		objs, err := d.list(ctx, member, dir.GetPath(), args.Refresh)
+		if err == nil {
+			return objs, nil
+		}
+		errs = append(errs, errors.WithMessagef(err, "failed in member [%s]", member))
+	}
+	return nil, utils.MergeErrors(errs...)
+}
+
+func (d *Mirror) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	var errs []error
+	for _, member := range d.readMembers() {
+		link, err := d.link(ctx, member, file.GetPath(), args)
+		if err == nil {
+			return link, nil
+		}
+		errs = append(errs, errors.WithMessagef(err, "failed in member [%s]", member))
+	}
+	return nil, utils.MergeErrors(errs...)
+}
+
+// the members are written through op, the hooks of fs (trash, versions, quota) already ran for the mirror
+
+func (d *Mirror) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	path := stdpath.Join(parentDir.GetPath(), dirName)
+	return d.fanOut(ctx, parentDir.GetPath(), func(ctx context.Context, member string) error {
+		storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, path))
+		if err != nil {
+			return err
+		}
+		return op.MakeDir(ctx, storage, actualPath)
+	})
+}
+
+func (d *Mirror) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	// the common parent covers both the src and the dst
+	return d.fanOut(ctx, commonDir(stdpath.Dir(srcObj.GetPath()), dstDir.GetPath()), func(ctx context.Context, member string) error {
+		srcStorage, srcActualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, srcObj.GetPath()))
+		if err != nil {
+			return err
+		}
+		dstStorage, dstActualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, dstDir.GetPath()))
+		if err != nil {
+			return err
+		}
+		if srcStorage.GetStorage() != dstStorage.GetStorage() {
+			return errs.MoveBetweenTwoStorages
+		}
+		return op.Move(ctx, srcStorage, srcActualPath, dstActualPath)
+	})
+}
+
+func (d *Mirror) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.fanOut(ctx, stdpath.Dir(srcObj.GetPath()), func(ctx context.Context, member string) error {
+		storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, srcObj.GetPath()))
+		if err != nil {
+			return err
+		}
+		return op.Rename(ctx, storage, actualPath, newName)
+	})
+}
+
+func (d *Mirror) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.fanOut(ctx, dstDir.GetPath(), func(ctx context.Context, member string) error {
+		return copyTo(ctx, stdpath.Join(member, srcObj.GetPath()), stdpath.Join(member, dstDir.GetPath()))
+	})
+}
+
+func (d *Mirror) Remove(ctx context.Context, obj model.Obj) error {
+	return d.fanOut(ctx, stdpath.Dir(obj.GetPath()), func(ctx context.Context, member string) error {
+		storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, obj.GetPath()))
+		if err != nil {
+			return err
+		}
+		err = op.Remove(ctx, storage, actualPath)
+		if errs.IsObjectNotFound(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Put uploads the stream to the healthiest member, then copies it from there to the others
+func (d *Mirror) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	members := d.readMembers()
+	primary := members[0]
+	storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(primary, dstDir.GetPath()))
+	if err != nil {
+		return errors.WithMessagef(err, "failed in member [%s]", primary)
+	}
+	if err = op.Put(ctx, storage, actualPath, stream, up); err != nil {
+		return errors.WithMessagef(err, "failed in member [%s]", primary)
+	}
+	path := stdpath.Join(dstDir.GetPath(), stream.GetName())
+	src := stdpath.Join(primary, path)
+	return d.replicateTo(ctx, members, path, func(ctx context.Context, member string) error {
+		return copyTo(ctx, src, stdpath.Join(member, dstDir.GetPath()))
+	})
+}
+
+// GetDetails reports the smallest member, which bounds what can be mirrored
+func (d *Mirror) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var res *model.StorageDetails
+	for _, member := range d.members {
+		details, err := d.details(ctx, member)
+		if err != nil {
+			continue
+		}
+		if res == nil || details.FreeSpace < res.FreeSpace {
+			res = details
+		}
+	}
+	if res == nil {
+		return nil, errs.NotImplement
+	}
+	return res, nil
+}
+
+func (d *Mirror) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	path := args.Obj.GetPath()
+	switch args.Method {
+	case "check", "repair":
+		t := fs.OtherAsTask(ctx, stdpath.Join(d.MountPath, path), args.Method, func(t *fs.OtherTask) (interface{}, error) {
+			t.SetStatus("checking")
+			report, err := d.check(t.Ctx(), d.members[0], path)
+			if err != nil || args.Method == "check" {
+				return report, err
+			}
+			t.SetStatus("repairing")
+			return report, d.repair(t.Ctx(), report, false)
+		})
+		return map[string]string{"task_id": t.GetID()}, nil
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
+func (d *Mirror) IsWriteMethod(method string) bool {
+	return method == "repair"
+}
+
+var _ driver.Driver = (*Mirror)(nil)
+var _ driver.WriteOther = (*Mirror)(nil)
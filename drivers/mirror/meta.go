@@ -0,0 +1,25 @@
+package mirror
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	Paths string `json:"paths" required:"true" type:"text" help:"one alist path per line, the first one is the reference for consistency check, repair copies files missing in any member"`
+	Async bool   `json:"async" default:"false" help:"write to the healthiest member only and replicate to the others in background, paths dropped by a full queue are synced later"`
+}
+
+var config = driver.Config{
+	Name:             "Mirror",
+	LocalSort:        true,
+	NoCache:          true,
+	DefaultRoot:      "/",
+	ProxyRangeOption: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Mirror{}
+	})
+}
@@ -0,0 +1,214 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sort"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/health"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const queueSize = 1024
+
+type replication struct {
+	ctx    context.Context
+	member string
+	f      func(ctx context.Context, member string) error
+}
+
+// readMembers returns the members ordered by health, healthy ones with lower latency first
+func (d *Mirror) readMembers() []string {
+	type rank struct {
+		member  string
+		healthy bool
+		latency int64
+	}
+	ranks := make([]rank, len(d.members))
+	for i, member := range d.members {
+		ranks[i] = rank{member: member, healthy: true}
+		storage, _, err := op.GetStorageAndActualPath(member)
+		if err != nil {
+			ranks[i].healthy = false
+			continue
+		}
+		if status, ok := health.Get(storage.GetStorage().ID); ok {
+			ranks[i].healthy = status.Healthy
+			ranks[i].latency = status.Latency
+		}
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		if ranks[i].healthy != ranks[j].healthy {
+			return ranks[i].healthy
+		}
+		return ranks[i].latency < ranks[j].latency
+	})
+	res := make([]string, len(ranks))
+	for i, r := range ranks {
+		res[i] = r.member
+	}
+	return res
+}
+
+func (d *Mirror) list(ctx context.Context, member, path string, refresh bool) ([]model.Obj, error) {
+	objs, err := fs.List(ctx, stdpath.Join(member, path), &fs.ListArgs{NoLog: true, Refresh: refresh})
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(objs, func(obj model.Obj) (model.Obj, error) {
+		objRes := model.Object{
+			Path:     stdpath.Join(path, obj.GetName()),
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			IsFolder: obj.IsDir(),
+			HashInfo: obj.GetHash(),
+		}
+		thumb, ok := model.GetThumb(obj)
+		if !ok {
+			return &objRes, nil
+		}
+		return &model.ObjThumb{
+			Object: objRes,
+			Thumbnail: model.Thumbnail{
+				Thumbnail: thumb,
+			},
+		}, nil
+	})
+}
+
+func (d *Mirror) link(ctx context.Context, member, path string, args model.LinkArgs) (*model.Link, error) {
+	reqPath := stdpath.Join(member, path)
+	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true}); err != nil {
+		return nil, err
+	}
+	if common.ShouldProxy(storage, stdpath.Base(path)) {
+		link := &model.Link{
+			URL: fmt.Sprintf("%s/p%s?sign=%s",
+				common.GetApiUrl(args.HttpReq),
+				utils.EncodePath(reqPath, true),
+				sign.Sign(reqPath)),
+		}
+		if args.HttpReq != nil && d.ProxyRange {
+			link.RangeReadCloser = common.NoProxyRange
+		}
+		return link, nil
+	}
+	link, _, err := fs.Link(ctx, reqPath, args)
+	return link, err
+}
+
+func (d *Mirror) details(ctx context.Context, member string) (*model.StorageDetails, error) {
+	storage, _, err := op.GetStorageAndActualPath(member)
+	if err != nil {
+		return nil, err
+	}
+	return op.GetStorageDetails(ctx, storage)
+}
+
+// fanOut applies f to the healthiest member, then replicates it to the others,
+// path is the mirror path repaired if the replication is dropped
+func (d *Mirror) fanOut(ctx context.Context, path string, f func(ctx context.Context, member string) error) error {
+	members := d.readMembers()
+	if err := f(ctx, members[0]); err != nil {
+		return errors.WithMessagef(err, "failed in member [%s]", members[0])
+	}
+	return d.replicateTo(ctx, members, path, f)
+}
+
+// replicateTo applies f to the members after the first one, which already has the change,
+// synchronously, or queues it in async mode
+func (d *Mirror) replicateTo(ctx context.Context, members []string, path string, f func(ctx context.Context, member string) error) error {
+	if d.Async && d.queue != nil {
+		// the request ctx is canceled once the response is sent
+		replCtx := context.Background()
+		if user, ok := ctx.Value("user").(*model.User); ok {
+			replCtx = context.WithValue(replCtx, "user", user)
+		}
+		for _, member := range members[1:] {
+			select {
+			case d.queue <- replication{ctx: replCtx, member: member, f: f}:
+			default:
+				// don't block the request, the path is synced from the first member once the queue drained
+				log.Warnf("[mirror] replication queue of [%s] is full, [%s] will be repaired later", d.MountPath, path)
+				d.dropped.Store(path, members[0])
+				return nil
+			}
+		}
+		return nil
+	}
+	var errs []error
+	for _, member := range members[1:] {
+		if err := f(ctx, member); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed in member [%s]", member))
+		}
+	}
+	return utils.MergeErrors(errs...)
+}
+
+// commonDir returns the deepest dir containing both dirs
+func commonDir(a, b string) string {
+	for !utils.IsSubPath(a, b) {
+		a = stdpath.Dir(a)
+	}
+	return a
+}
+
+// copyTo copies src into dstDir, both are mount paths
+func copyTo(ctx context.Context, src, dstDir string) error {
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(src)
+	if err != nil {
+		return err
+	}
+	dstStorage, dstActualPath, err := op.GetStorageAndActualPath(dstDir)
+	if err != nil {
+		return err
+	}
+	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+		return op.Copy(ctx, srcStorage, srcActualPath, dstActualPath)
+	}
+	return fs.CopyDirectly(ctx, srcStorage, srcActualPath, dstStorage, dstActualPath)
+}
+
+func (d *Mirror) replicate(ctx context.Context, queue chan replication) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case r := <-queue:
+			if err := r.f(r.ctx, r.member); err != nil {
+				log.Errorf("[mirror] failed replicate to [%s]: %+v", r.member, err)
+			}
+			if len(queue) == 0 {
+				d.repairDropped(ctx)
+			}
+		}
+	}
+}
+
+// repairDropped syncs the paths whose replication was dropped from the member having the change
+func (d *Mirror) repairDropped(ctx context.Context) {
+	d.dropped.Range(func(path, reference string) bool {
+		d.dropped.Delete(path)
+		report, err := d.check(ctx, reference, path)
+		if err == nil {
+			err = d.repair(ctx, report, true)
+		}
+		if err != nil {
+			log.Errorf("[mirror] failed repair [%s] of [%s]: %+v", path, d.MountPath, err)
+		}
+		return ctx.Err() == nil
+	})
+}
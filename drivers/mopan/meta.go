@@ -27,7 +27,8 @@ func (a *Addition) GetRootId() string {
 }
 
 var config = driver.Config{
-	Name: "MoPan",
+	Name:      "MoPan",
+	NeedStore: true,
 	// DefaultRoot: "root, / or other",
 	CheckStatus: true,
 	Alert:       "warning|This network disk may store your password in clear text. Please set your password carefully",
@@ -22,7 +22,8 @@ func (ad *Addition) getCookie(name string) string {
 }
 
 var config = driver.Config{
-	Name: "NeteaseMusic",
+	Name:      "NeteaseMusic",
+	NeedStore: true,
 }
 
 func init() {
@@ -18,6 +18,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:        "PikPak",
+	NeedStore:   true,
 	LocalSort:   true,
 	DefaultRoot: "",
 }
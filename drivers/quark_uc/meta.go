@@ -24,6 +24,7 @@ func init() {
 		return &QuarkOrUC{
 			config: driver.Config{
 				Name:              "Quark",
+				NeedStore:         true,
 				OnlyLocal:         true,
 				DefaultRoot:       "0",
 				NoOverwriteUpload: true,
@@ -40,6 +41,7 @@ func init() {
 		return &QuarkOrUC{
 			config: driver.Config{
 				Name:              "UC",
+				NeedStore:         true,
 				OnlyLocal:         true,
 				DefaultRoot:       "0",
 				NoOverwriteUpload: true,
@@ -15,6 +15,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:      "Quqi",
+	NeedStore: true,
 	OnlyLocal: true,
 	LocalSort: true,
 	//NoUpload:    true,
@@ -3,6 +3,8 @@ package s3
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/alist-org/alist/v3/server/common"
 	"io"
@@ -15,6 +17,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/cron"
 
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -31,6 +34,8 @@ type S3 struct {
 
 	config driver.Config
 	cron   *cron.Cron
+	// decoded SSECustomerKey
+	customerKey string
 }
 
 func (d *S3) Config() driver.Config {
@@ -45,6 +50,15 @@ func (d *S3) Init(ctx context.Context) error {
 	if d.Region == "" {
 		d.Region = "alist"
 	}
+	if d.ServerSideEncryption == sseCustomer {
+		key, err := base64.StdEncoding.DecodeString(d.SSECustomerKey)
+		if err != nil || len(key) != 32 {
+			return errors.New("sse customer key must be a base64 encoded 32 bytes key")
+		}
+		d.customerKey = string(key)
+		// the key has to be sent with every download
+		d.config.OnlyProxy = true
+	}
 	if d.config.Name == "Doge" {
 		// 多吉云每次临时生成的秘钥有效期为 2h，所以这里设置为 118 分钟重新生成一次
 		d.cron = cron.NewCron(time.Minute * 118)
@@ -74,6 +88,9 @@ func (d *S3) Drop(ctx context.Context) error {
 }
 
 func (d *S3) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	if d.ShowVersions {
+		return d.listVersions(dir.GetPath(), args)
+	}
 	if d.ListObjectVersion == "v2" {
 		return d.listV2(dir.GetPath(), args)
 	}
@@ -81,7 +98,7 @@ func (d *S3) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]mo
 }
 
 func (d *S3) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	path := getKey(file.GetPath(), false)
+	path, versionID := d.objKey(file)
 	filename := stdpath.Base(path)
 	disposition := fmt.Sprintf(`attachment; filename*=UTF-8''%s`, url.PathEscape(filename))
 	if d.AddFilenameToDisposition {
@@ -92,13 +109,19 @@ func (d *S3) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*mo
 		Key:    &path,
 		//ResponseContentDisposition: &disposition,
 	}
+	if versionID != "" {
+		input.VersionId = &versionID
+	}
+	sseAlgorithm, sseKey := d.customerKeyOptions()
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseAlgorithm, sseKey
 	if d.CustomHost == "" {
 		input.ResponseContentDisposition = &disposition
 	}
 	req, _ := d.linkClient.GetObjectRequest(input)
 	var link model.Link
 	var err error
-	if d.CustomHost != "" {
+	// presigned urls can't carry the customer key, it's sent in the proxied headers
+	if d.CustomHost != "" && sseKey == nil {
 		if d.EnableCustomHostPresign {
 			link.URL, err = req.Presign(time.Hour * time.Duration(d.SignURLExpire))
 		} else {
@@ -113,6 +136,10 @@ func (d *S3) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*mo
 			err = req.Sign()
 			link.URL = req.HTTPRequest.URL.String()
 			link.Header = req.HTTPRequest.Header
+			// proxied reads retry without the key for objects written before it was configured
+			if sseKey != nil {
+				link.RangeReadCloser = &model.RangeReadCloser{RangeReader: d.rangeReader(path, versionID)}
+			}
 		} else {
 			link.URL, err = req.Presign(time.Hour * time.Duration(d.SignURLExpire))
 		}
@@ -145,7 +172,7 @@ func (d *S3) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
 }
 
 func (d *S3) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
-	err := d.copy(ctx, srcObj.GetPath(), stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName), srcObj.IsDir())
+	err := d.copy(ctx, srcObj, stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName))
 	if err != nil {
 		return err
 	}
@@ -153,14 +180,15 @@ func (d *S3) Rename(ctx context.Context, srcObj model.Obj, newName string) error
 }
 
 func (d *S3) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
-	return d.copy(ctx, srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()), srcObj.IsDir())
+	return d.copy(ctx, srcObj, stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
 }
 
 func (d *S3) Remove(ctx context.Context, obj model.Obj) error {
 	if obj.IsDir() {
 		return d.removeDir(ctx, obj.GetPath())
 	}
-	return d.removeFile(obj.GetPath())
+	key, versionID := d.objKey(obj)
+	return d.removeObject(key, versionID)
 }
 
 func (d *S3) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
@@ -171,14 +199,60 @@ func (d *S3) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreame
 	key := getKey(stdpath.Join(dstDir.GetPath(), stream.GetName()), false)
 	contentType := stream.GetMimetype()
 	log.Debugln("key:", key)
+	opts := d.writeOptions()
 	input := &s3manager.UploadInput{
-		Bucket:      &d.Bucket,
-		Key:         &key,
-		Body:        stream,
-		ContentType: &contentType,
+		Bucket:               &d.Bucket,
+		Key:                  &key,
+		Body:                 io.TeeReader(stream, driver.NewProgress(stream.GetSize(), up)),
+		ContentType:          &contentType,
+		StorageClass:         opts.StorageClass,
+		ServerSideEncryption: opts.ServerSideEncryption,
+		SSEKMSKeyId:          opts.SSEKMSKeyID,
+		SSECustomerAlgorithm: opts.SSECustomerAlgorithm,
+		SSECustomerKey:       opts.SSECustomerKey,
 	}
 	_, err := uploader.UploadWithContext(ctx, input)
 	return err
 }
 
+func (d *S3) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	if args.Obj.IsDir() {
+		return nil, errs.NotFile
+	}
+	key, versionID := d.objKey(args.Obj)
+	switch args.Method {
+	case "info":
+		return d.info(ctx, key, versionID)
+	case "versions":
+		return d.versions(ctx, key)
+	case "restore_version":
+		// copying an old version onto the key makes it the latest one
+		var v VersionArgs
+		if err := decodeArgs(args.Data, &v); err != nil {
+			return nil, err
+		}
+		if v.VersionID == "" {
+			v.VersionID = versionID
+		}
+		if v.VersionID == "" {
+			return nil, errors.New("version_id is required")
+		}
+		return nil, d.copyObject(ctx, key, v.VersionID, key)
+	case "restore":
+		var r RestoreArgs
+		if err := decodeArgs(args.Data, &r); err != nil {
+			return nil, err
+		}
+		return nil, d.restore(ctx, key, versionID, r)
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
+func (d *S3) IsWriteMethod(method string) bool {
+	return method == "restore_version" || method == "restore"
+}
+
 var _ driver.Driver = (*S3)(nil)
+var _ driver.Other = (*S3)(nil)
+var _ driver.WriteOther = (*S3)(nil)
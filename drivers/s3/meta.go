@@ -21,6 +21,11 @@ type Addition struct {
 	ListObjectVersion        string `json:"list_object_version" type:"select" options:"v1,v2" default:"v1"`
 	RemoveBucket             bool   `json:"remove_bucket" help:"Remove bucket name from path when using custom host."`
 	AddFilenameToDisposition bool   `json:"add_filename_to_disposition" help:"Add filename to Content-Disposition header."`
+	StorageClass             string `json:"storage_class" help:"Storage class of uploaded and copied objects, e.g. STANDARD_IA, GLACIER. Empty uses the default of the bucket."`
+	ServerSideEncryption     string `json:"server_side_encryption" type:"select" options:"none,AES256,aws:kms,customer" default:"none" help:"customer encrypts with the key below, links are proxied to send it."`
+	SSEKMSKeyID              string `json:"sse_kms_key_id" help:"KMS key id for aws:kms, empty uses the default key."`
+	SSECustomerKey           string `json:"sse_customer_key" help:"Base64 encoded 256-bit key for customer encryption."`
+	ShowVersions             bool   `json:"show_versions" help:"List old versions of objects in versioned buckets as name~time.ext"`
 }
 
 func init() {
@@ -1 +1,20 @@
 package s3
+
+import "github.com/alist-org/alist/v3/internal/model"
+
+// Object is an s3 object, old versions of a key are listed as objects of their own
+type Object struct {
+	model.Object
+	Key          string
+	VersionID    string // set for old versions only
+	StorageClass string
+}
+
+type RestoreArgs struct {
+	Days int    `json:"days"`
+	Tier string `json:"tier"` // Standard, Bulk or Expedited
+}
+
+type VersionArgs struct {
+	VersionID string `json:"version_id"`
+}
@@ -3,18 +3,27 @@ package s3
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"path"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -186,25 +195,318 @@ func (d *S3) listV2(prefix string, args model.ListArgs) ([]model.Obj, error) {
 	return files, nil
 }
 
-func (d *S3) copy(ctx context.Context, src string, dst string, isDir bool) error {
-	if isDir {
-		return d.copyDir(ctx, src, dst)
+func (d *S3) listVersions(prefix string, args model.ListArgs) ([]model.Obj, error) {
+	prefix = getKey(prefix, true)
+	files := make([]model.Obj, 0)
+	names := make(map[string]struct{})
+	var keyMarker, versionIdMarker *string
+	for {
+		input := &s3.ListObjectVersionsInput{
+			Bucket:          &d.Bucket,
+			Prefix:          &prefix,
+			Delimiter:       aws.String("/"),
+			KeyMarker:       keyMarker,
+			VersionIdMarker: versionIdMarker,
+		}
+		listVersionsResult, err := d.client.ListObjectVersions(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range listVersionsResult.CommonPrefixes {
+			name := path.Base(strings.Trim(*object.Prefix, "/"))
+			file := model.Object{
+				Name:     name,
+				Modified: d.Modified,
+				IsFolder: true,
+			}
+			files = append(files, &file)
+		}
+		for _, version := range listVersionsResult.Versions {
+			if strings.HasSuffix(*version.Key, "/") {
+				continue
+			}
+			name := path.Base(*version.Key)
+			if !args.S3ShowPlaceholder && (name == getPlaceholderName(d.Placeholder) || name == d.Placeholder) {
+				continue
+			}
+			file := &Object{
+				Object: model.Object{
+					Name:     name,
+					Size:     aws.Int64Value(version.Size),
+					Modified: aws.TimeValue(version.LastModified),
+				},
+				Key:          *version.Key,
+				StorageClass: aws.StringValue(version.StorageClass),
+			}
+			if !aws.BoolValue(version.IsLatest) {
+				file.VersionID = aws.StringValue(version.VersionId)
+				file.Name = versionName(name, file.Modified, file.VersionID, names)
+			}
+			names[file.Name] = struct{}{}
+			files = append(files, file)
+		}
+		if !aws.BoolValue(listVersionsResult.IsTruncated) {
+			break
+		}
+		keyMarker = listVersionsResult.NextKeyMarker
+		versionIdMarker = listVersionsResult.NextVersionIdMarker
+	}
+	return files, nil
+}
+
+// versionName names an old version as name~time.ext, the version id is added if two versions have the same time
+func versionName(name string, modified time.Time, versionID string, names map[string]struct{}) string {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext) + "~" + modified.UTC().Format("20060102150405")
+	if _, ok := names[stem+ext]; ok {
+		stem += "-" + versionID
+	}
+	return stem + ext
+}
+
+// objKey returns the key of the obj and its version id if it's an old version
+func (d *S3) objKey(obj model.Obj) (string, string) {
+	if o, ok := model.UnwrapObj(obj).(*Object); ok {
+		return o.Key, o.VersionID
+	}
+	return getKey(obj.GetPath(), false), ""
+}
+
+func decodeArgs(data interface{}, v interface{}) error {
+	if data == nil {
+		return nil
+	}
+	b, err := utils.Json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err = utils.Json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("invalid args: %w", err)
+	}
+	return nil
+}
+
+const (
+	sseNone     = "none"
+	sseCustomer = "customer"
+	// larger objects can't be copied with a single CopyObject
+	maxCopyObjectSize = 5 * 1024 * 1024 * 1024
+	minCopyPartSize   = 512 * 1024 * 1024
+)
+
+type writeOptions struct {
+	StorageClass         *string
+	ServerSideEncryption *string
+	SSEKMSKeyID          *string
+	SSECustomerAlgorithm *string
+	SSECustomerKey       *string
+}
+
+// writeOptions returns the storage class and encryption set on the objects written
+func (d *S3) writeOptions() writeOptions {
+	var opts writeOptions
+	if d.StorageClass != "" {
+		opts.StorageClass = &d.StorageClass
+	}
+	switch d.ServerSideEncryption {
+	case "", sseNone:
+	case sseCustomer:
+		opts.SSECustomerAlgorithm = aws.String(s3.ServerSideEncryptionAes256)
+		opts.SSECustomerKey = &d.customerKey
+	default:
+		opts.ServerSideEncryption = &d.ServerSideEncryption
+		if d.ServerSideEncryption == s3.ServerSideEncryptionAwsKms && d.SSEKMSKeyID != "" {
+			opts.SSEKMSKeyID = &d.SSEKMSKeyID
+		}
+	}
+	return opts
+}
+
+// customerKeyOptions returns the customer key headers to read with, nil if no key is set
+func (d *S3) customerKeyOptions() (algorithm, customerKey *string) {
+	if d.customerKey == "" {
+		return nil, nil
+	}
+	return aws.String(s3.ServerSideEncryptionAes256), &d.customerKey
+}
+
+// isSSEMismatch reports whether the read failed because the customer key was sent for an object
+// not encrypted with it, or missing for one that is. HEAD responses have no body, so a bare 400
+// is all they tell
+func isSSEMismatch(err error) bool {
+	var reqErr awserr.RequestFailure
+	if !errors.As(err, &reqErr) || reqErr.StatusCode() != http.StatusBadRequest {
+		return false
+	}
+	switch reqErr.Code() {
+	case "BadRequest":
+		return reqErr.Message() == ""
+	case "InvalidRequest", "InvalidArgument":
+		return strings.Contains(strings.ToLower(reqErr.Message()), "encryption")
 	}
-	return d.copyFile(ctx, src, dst)
+	return false
 }
 
-func (d *S3) copyFile(ctx context.Context, src string, dst string) error {
-	srcKey := getKey(src, false)
-	dstKey := getKey(dst, false)
+// withCustomerKey reads with the customer key if it's set, and once more without it if the object
+// was written before the key was configured, returns the key headers the read succeeded with
+func (d *S3) withCustomerKey(read func(algorithm, customerKey *string) error) (algorithm, customerKey *string, err error) {
+	algorithm, customerKey = d.customerKeyOptions()
+	err = read(algorithm, customerKey)
+	if customerKey == nil || !isSSEMismatch(err) {
+		return algorithm, customerKey, err
+	}
+	return nil, nil, read(nil, nil)
+}
+
+// rangeReader reads the object with the customer key, or without it if the object isn't encrypted with it
+func (d *S3) rangeReader(key, versionID string) model.RangeReaderFunc {
+	return func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		input := &s3.GetObjectInput{
+			Bucket:    &d.Bucket,
+			Key:       &key,
+			VersionId: nilIfEmpty(versionID),
+		}
+		if httpRange.Start != 0 || httpRange.Length >= 0 {
+			input.Range = aws.String(http_range.ApplyRangeToHttpHeader(httpRange, nil).Get("Range"))
+		}
+		var out *s3.GetObjectOutput
+		_, _, err := d.withCustomerKey(func(algorithm, customerKey *string) (err error) {
+			input.SSECustomerAlgorithm, input.SSECustomerKey = algorithm, customerKey
+			out, err = d.client.GetObjectWithContext(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return out.Body, nil
+	}
+}
+
+func (d *S3) copy(ctx context.Context, srcObj model.Obj, dst string) error {
+	if srcObj.IsDir() {
+		return d.copyDir(ctx, srcObj.GetPath(), dst)
+	}
+	key, versionID := d.objKey(srcObj)
+	return d.copyObject(ctx, key, versionID, getKey(dst, false))
+}
+
+func (d *S3) copyObject(ctx context.Context, srcKey, versionID, dstKey string) error {
+	opts := d.writeOptions()
+	var head *s3.HeadObjectOutput
+	srcAlgorithm, srcCustomerKey, err := d.withCustomerKey(func(algorithm, customerKey *string) (err error) {
+		head, err = d.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket:               &d.Bucket,
+			Key:                  &srcKey,
+			VersionId:            nilIfEmpty(versionID),
+			SSECustomerAlgorithm: algorithm,
+			SSECustomerKey:       customerKey,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	source := "/" + d.Bucket + "/" + url.PathEscape(srcKey)
+	if versionID != "" {
+		source += "?versionId=" + url.QueryEscape(versionID)
+	}
+	src := copySource{source: source, algorithm: srcAlgorithm, customerKey: srcCustomerKey}
+	if aws.Int64Value(head.ContentLength) > maxCopyObjectSize {
+		return d.multipartCopy(ctx, src, dstKey, aws.Int64Value(head.ContentLength), head.ContentType)
+	}
 	input := &s3.CopyObjectInput{
-		Bucket:     &d.Bucket,
-		CopySource: aws.String("/" + d.Bucket + "/" + srcKey),
-		Key:        &dstKey,
+		Bucket:                         &d.Bucket,
+		CopySource:                     &src.source,
+		Key:                            &dstKey,
+		StorageClass:                   opts.StorageClass,
+		ServerSideEncryption:           opts.ServerSideEncryption,
+		SSEKMSKeyId:                    opts.SSEKMSKeyID,
+		SSECustomerAlgorithm:           opts.SSECustomerAlgorithm,
+		SSECustomerKey:                 opts.SSECustomerKey,
+		CopySourceSSECustomerAlgorithm: src.algorithm,
+		CopySourceSSECustomerKey:       src.customerKey,
+	}
+	_, err = d.client.CopyObjectWithContext(ctx, input)
+	return err
+}
+
+type copySource struct {
+	source      string
+	algorithm   *string
+	customerKey *string
+}
+
+// copyPartSize returns the part size to copy an object of the size within s3manager.MaxUploadParts parts
+func copyPartSize(size int64) int64 {
+	partSize := int64(minCopyPartSize)
+	if size/partSize >= s3manager.MaxUploadParts {
+		partSize = size/(s3manager.MaxUploadParts-1) + 1
+	}
+	return partSize
+}
+
+// multipartCopy copies the source in parts with UploadPartCopy
+func (d *S3) multipartCopy(ctx context.Context, src copySource, dstKey string, size int64, contentType *string) error {
+	opts := d.writeOptions()
+	created, err := d.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               &d.Bucket,
+		Key:                  &dstKey,
+		ContentType:          contentType,
+		StorageClass:         opts.StorageClass,
+		ServerSideEncryption: opts.ServerSideEncryption,
+		SSEKMSKeyId:          opts.SSEKMSKeyID,
+		SSECustomerAlgorithm: opts.SSECustomerAlgorithm,
+		SSECustomerKey:       opts.SSECustomerKey,
+	})
+	if err != nil {
+		return err
+	}
+	partSize := copyPartSize(size)
+	var parts []*s3.CompletedPart
+	for num, start := int64(1), int64(0); start < size; num, start = num+1, start+partSize {
+		end := min(start+partSize, size) - 1
+		res, err := d.client.UploadPartCopyWithContext(ctx, &s3.UploadPartCopyInput{
+			Bucket:                         &d.Bucket,
+			Key:                            &dstKey,
+			UploadId:                       created.UploadId,
+			PartNumber:                     aws.Int64(num),
+			CopySource:                     &src.source,
+			CopySourceRange:                aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			SSECustomerAlgorithm:           opts.SSECustomerAlgorithm,
+			SSECustomerKey:                 opts.SSECustomerKey,
+			CopySourceSSECustomerAlgorithm: src.algorithm,
+			CopySourceSSECustomerKey:       src.customerKey,
+		})
+		if err != nil {
+			d.abortMultipartUpload(dstKey, created.UploadId)
+			return err
+		}
+		parts = append(parts, &s3.CompletedPart{ETag: res.CopyPartResult.ETag, PartNumber: aws.Int64(num)})
+	}
+	_, err = d.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &d.Bucket,
+		Key:             &dstKey,
+		UploadId:        created.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		d.abortMultipartUpload(dstKey, created.UploadId)
 	}
-	_, err := d.client.CopyObject(input)
 	return err
 }
 
+func (d *S3) abortMultipartUpload(key string, uploadId *string) {
+	// not bound to ctx, it may have been canceled
+	_, err := d.client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   &d.Bucket,
+		Key:      &key,
+		UploadId: uploadId,
+	})
+	if err != nil {
+		log.Errorf("failed abort multipart upload of [%s]: %+v", key, err)
+	}
+}
+
 func (d *S3) copyDir(ctx context.Context, src string, dst string) error {
 	objs, err := op.List(ctx, d, src, model.ListArgs{S3ShowPlaceholder: true})
 	if err != nil {
@@ -216,7 +518,12 @@ func (d *S3) copyDir(ctx context.Context, src string, dst string) error {
 		if obj.IsDir() {
 			err = d.copyDir(ctx, cSrc, cDst)
 		} else {
-			err = d.copyFile(ctx, cSrc, cDst)
+			key, versionID := d.objKey(obj)
+			if versionID != "" {
+				// only the latest versions are copied
+				continue
+			}
+			err = d.copyObject(ctx, key, "", getKey(cDst, false))
 		}
 		if err != nil {
 			return err
@@ -235,23 +542,134 @@ func (d *S3) removeDir(ctx context.Context, src string) error {
 		if obj.IsDir() {
 			err = d.removeDir(ctx, cSrc)
 		} else {
-			err = d.removeFile(cSrc)
+			key, versionID := d.objKey(obj)
+			err = d.removeObject(key, versionID)
 		}
 		if err != nil {
 			return err
 		}
 	}
-	_ = d.removeFile(path.Join(src, getPlaceholderName(d.Placeholder)))
-	_ = d.removeFile(path.Join(src, d.Placeholder))
+	_ = d.removeObject(getKey(path.Join(src, getPlaceholderName(d.Placeholder)), false), "")
+	_ = d.removeObject(getKey(path.Join(src, d.Placeholder), false), "")
 	return nil
 }
 
-func (d *S3) removeFile(src string) error {
-	key := getKey(src, false)
+// removeObject deletes the key, or only the given version of it
+func (d *S3) removeObject(key, versionID string) error {
 	input := &s3.DeleteObjectInput{
-		Bucket: &d.Bucket,
-		Key:    &key,
+		Bucket:    &d.Bucket,
+		Key:       &key,
+		VersionId: nilIfEmpty(versionID),
 	}
 	_, err := d.client.DeleteObject(input)
 	return err
 }
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// info returns the storage class, restore status and encryption of the object
+func (d *S3) info(ctx context.Context, key, versionID string) (base.Json, error) {
+	var head *s3.HeadObjectOutput
+	_, _, err := d.withCustomerKey(func(algorithm, customerKey *string) (err error) {
+		head, err = d.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket:               &d.Bucket,
+			Key:                  &key,
+			VersionId:            nilIfEmpty(versionID),
+			SSECustomerAlgorithm: algorithm,
+			SSECustomerKey:       customerKey,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	storageClass := aws.StringValue(head.StorageClass)
+	if storageClass == "" {
+		storageClass = s3.StorageClassStandard
+	}
+	return base.Json{
+		"key":                    key,
+		"version_id":             aws.StringValue(head.VersionId),
+		"size":                   aws.Int64Value(head.ContentLength),
+		"modified":               aws.TimeValue(head.LastModified),
+		"etag":                   aws.StringValue(head.ETag),
+		"storage_class":          storageClass,
+		"restore":                aws.StringValue(head.Restore),
+		"server_side_encryption": aws.StringValue(head.ServerSideEncryption),
+		"sse_kms_key_id":         aws.StringValue(head.SSEKMSKeyId),
+		"sse_customer_algorithm": aws.StringValue(head.SSECustomerAlgorithm),
+	}, nil
+}
+
+// versions lists all versions and delete markers of the key, newest first
+func (d *S3) versions(ctx context.Context, key string) ([]base.Json, error) {
+	res := make([]base.Json, 0)
+	var keyMarker, versionIdMarker *string
+	for {
+		out, err := d.client.ListObjectVersionsWithContext(ctx, &s3.ListObjectVersionsInput{
+			Bucket:          &d.Bucket,
+			Prefix:          &key,
+			KeyMarker:       keyMarker,
+			VersionIdMarker: versionIdMarker,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range out.Versions {
+			if aws.StringValue(v.Key) != key {
+				continue
+			}
+			res = append(res, base.Json{
+				"version_id":    aws.StringValue(v.VersionId),
+				"is_latest":     aws.BoolValue(v.IsLatest),
+				"size":          aws.Int64Value(v.Size),
+				"modified":      aws.TimeValue(v.LastModified),
+				"storage_class": aws.StringValue(v.StorageClass),
+			})
+		}
+		for _, m := range out.DeleteMarkers {
+			if aws.StringValue(m.Key) != key {
+				continue
+			}
+			res = append(res, base.Json{
+				"version_id":       aws.StringValue(m.VersionId),
+				"is_latest":        aws.BoolValue(m.IsLatest),
+				"modified":         aws.TimeValue(m.LastModified),
+				"is_delete_marker": true,
+			})
+		}
+		if !aws.BoolValue(out.IsTruncated) {
+			break
+		}
+		keyMarker, versionIdMarker = out.NextKeyMarker, out.NextVersionIdMarker
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i]["modified"].(time.Time).After(res[j]["modified"].(time.Time))
+	})
+	return res, nil
+}
+
+// restore restores an archived object, e.g. in GLACIER, for the given days
+func (d *S3) restore(ctx context.Context, key, versionID string, args RestoreArgs) error {
+	if args.Days <= 0 {
+		args.Days = 1
+	}
+	if args.Tier == "" {
+		args.Tier = s3.TierStandard
+	}
+	_, err := d.client.RestoreObjectWithContext(ctx, &s3.RestoreObjectInput{
+		Bucket:    &d.Bucket,
+		Key:       &key,
+		VersionId: nilIfEmpty(versionID),
+		RestoreRequest: &s3.RestoreRequest{
+			Days:                 aws.Int64(int64(args.Days)),
+			GlacierJobParameters: &s3.GlacierJobParameters{Tier: &args.Tier},
+		},
+	})
+	return err
+}
@@ -0,0 +1,70 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+func TestVersionName(t *testing.T) {
+	modified := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	names := map[string]struct{}{}
+	if got := versionName("a.tar.gz", modified, "v1", names); got != "a.tar~20240506070809.gz" {
+		t.Errorf("got %s", got)
+	}
+	if got := versionName("README", modified, "v1", names); got != "README~20240506070809" {
+		t.Errorf("got %s", got)
+	}
+	names["a~20240506070809.txt"] = struct{}{}
+	if got := versionName("a.txt", modified, "v2", names); got != "a~20240506070809-v2.txt" {
+		t.Errorf("same time should add the version id, got %s", got)
+	}
+	local := modified.In(time.FixedZone("UTC+8", 8*3600))
+	if got := versionName("a.txt", local, "v1", map[string]struct{}{}); got != "a~20240506070809.txt" {
+		t.Errorf("time should be utc, got %s", got)
+	}
+}
+
+func TestCopyPartSize(t *testing.T) {
+	const gb = int64(1024 * 1024 * 1024)
+	for _, size := range []int64{6 * gb, 100 * gb, 5000 * gb, 5 * 1024 * gb} {
+		partSize := copyPartSize(size)
+		if partSize < minCopyPartSize {
+			t.Errorf("size %d: part size %d is less than the min", size, partSize)
+		}
+		if parts := (size + partSize - 1) / partSize; parts > s3manager.MaxUploadParts {
+			t.Errorf("size %d: %d parts is more than the max", size, parts)
+		}
+	}
+	if got := copyPartSize(6 * gb); got != minCopyPartSize {
+		t.Errorf("small objects should use the min part size, got %d", got)
+	}
+}
+
+func TestIsSSEMismatch(t *testing.T) {
+	failure := func(status int, code, message string) error {
+		return awserr.NewRequestFailure(awserr.New(code, message, nil), status, "")
+	}
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{failure(http.StatusBadRequest, "BadRequest", ""), true},
+		{failure(http.StatusBadRequest, "InvalidRequest", "The encryption parameters are not applicable to this object."), true},
+		{failure(http.StatusBadRequest, "InvalidRequest", "The object was stored using a form of Server Side Encryption."), true},
+		{failure(http.StatusBadRequest, "InvalidArgument", "Invalid version id specified"), false},
+		{failure(http.StatusBadRequest, "BadRequest", "bad range"), false},
+		{failure(http.StatusForbidden, "AccessDenied", ""), false},
+		{errors.New("network error"), false},
+		{nil, false},
+	}
+	for i, c := range cases {
+		if got := isSSEMismatch(c.err); got != c.want {
+			t.Errorf("case %d: got %v, want %v", i, got, c.want)
+		}
+	}
+}
@@ -64,6 +64,9 @@ func (d *Seafile) request(method string, pathname string, callback base.ReqCallb
 			return nil, err
 		}
 	}
+	if res.StatusCode() == 401 {
+		return nil, errs.NewErr(errs.Unauthorized, "request failed: %s", res.String())
+	}
 	if res.StatusCode() >= 400 {
 		return nil, fmt.Errorf("request failed: %s", res.String())
 	}
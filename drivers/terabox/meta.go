@@ -16,6 +16,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:        "Terabox",
+	NeedStore:   true,
 	DefaultRoot: "/",
 }
 
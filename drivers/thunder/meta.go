@@ -83,12 +83,14 @@ func (i *Addition) GetIdentity() string {
 
 var config = driver.Config{
 	Name:      "Thunder",
+	NeedStore: true,
 	LocalSort: true,
 	OnlyProxy: true,
 }
 
 var configExpert = driver.Config{
 	Name:      "ThunderExpert",
+	NeedStore: true,
 	LocalSort: true,
 }
 
@@ -90,11 +90,13 @@ func (i *Addition) GetIdentity() string {
 
 var config = driver.Config{
 	Name:      "ThunderBrowser",
+	NeedStore: true,
 	LocalSort: true,
 }
 
 var configExpert = driver.Config{
 	Name:      "ThunderBrowserExpert",
+	NeedStore: true,
 	LocalSort: true,
 }
 
@@ -84,12 +84,14 @@ func (i *Addition) GetIdentity() string {
 
 var config = driver.Config{
 	Name:      "ThunderX",
+	NeedStore: true,
 	LocalSort: true,
 	OnlyProxy: false,
 }
 
 var configExpert = driver.Config{
 	Name:      "ThunderXExpert",
+	NeedStore: true,
 	LocalSort: true,
 }
 
@@ -0,0 +1,208 @@
+package union
+
+import (
+	"context"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+type Union struct {
+	model.Storage
+	Addition
+	members []string
+	rrIndex uint32
+}
+
+func (d *Union) Config() driver.Config {
+	return config
+}
+
+func (d *Union) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Union) Init(ctx context.Context) error {
+	d.members = nil
+	for _, path := range strings.Split(d.Paths, "\n") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		path = utils.FixAndCleanPath(path)
+		if utils.IsSubPath(d.MountPath, path) || utils.IsSubPath(path, d.MountPath) {
+			return errors.New("paths can't contain the union itself")
+		}
+		d.members = append(d.members, path)
+	}
+	if len(d.members) == 0 {
+		return errors.New("paths is required")
+	}
+	return d.checkCreatePolicy()
+}
+
+func (d *Union) Drop(ctx context.Context) error {
+	d.members = nil
+	return nil
+}
+
+func (d *Union) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	for _, member := range d.members {
+		obj, err := d.get(ctx, member, path)
+		if err == nil {
+			return &model.Object{
+				Path:     path,
+				Name:     obj.GetName(),
+				Size:     obj.GetSize(),
+				Modified: obj.ModTime(),
+				IsFolder: obj.IsDir(),
+				HashInfo: obj.GetHash(),
+			}, nil
+		}
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (d *Union) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var objs []model.Obj
+	names := make(map[string]struct{})
+	found := false
+	for _, member := range d.members {
+		tmp, err := d.list(ctx, member, dir.GetPath(), args.Refresh)
+		if err != nil {
+			continue
+		}
+		found = true
+		// same-name objects in later members are hidden
+		for _, obj := range tmp {
+			if _, ok := names[obj.GetName()]; ok {
+				continue
+			}
+			names[obj.GetName()] = struct{}{}
+			objs = append(objs, obj)
+		}
+	}
+	if !found {
+		return nil, errs.ObjectNotFound
+	}
+	return objs, nil
+}
+
+// Link returns the link of the first member owning the file,
+// the errors of the members are returned unless all of them just don't have it
+func (d *Union) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	var memberErrs []error
+	for _, member := range d.members {
+		link, err := d.link(ctx, member, file.GetPath(), args)
+		if err == nil {
+			return link, nil
+		}
+		if !errs.IsObjectNotFound(err) {
+			memberErrs = append(memberErrs, errors.WithMessagef(err, "failed in member [%s]", member))
+		}
+	}
+	if len(memberErrs) > 0 {
+		return nil, utils.MergeErrors(memberErrs...)
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (d *Union) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	member, err := d.createMember(ctx, parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, parentDir.GetPath(), dirName))
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, actualPath)
+}
+
+// Move moves the object within each member owning it
+func (d *Union) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.forEachOwner(ctx, srcObj.GetPath(), func(member string) error {
+		return d.moveOrCopy(ctx, member, srcObj.GetPath(), dstDir.GetPath(), false)
+	})
+}
+
+func (d *Union) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.forEachOwner(ctx, srcObj.GetPath(), func(member string) error {
+		storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, srcObj.GetPath()))
+		if err != nil {
+			return err
+		}
+		return op.Rename(ctx, storage, actualPath, newName)
+	})
+}
+
+// Copy copies the object within the first member owning it
+func (d *Union) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	owners := d.owners(ctx, srcObj.GetPath())
+	if len(owners) == 0 {
+		return errs.ObjectNotFound
+	}
+	return d.moveOrCopy(ctx, owners[0], srcObj.GetPath(), dstDir.GetPath(), true)
+}
+
+// Remove removes the object from all members owning it
+func (d *Union) Remove(ctx context.Context, obj model.Obj) error {
+	return d.forEachOwner(ctx, obj.GetPath(), func(member string) error {
+		storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, obj.GetPath()))
+		if err != nil {
+			return err
+		}
+		return op.Remove(ctx, storage, actualPath)
+	})
+}
+
+// Put overwrites the existing file in the member owning it, or creates it in the member chosen by the create policy
+func (d *Union) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	var member string
+	if owners := d.owners(ctx, stdpath.Join(dstDir.GetPath(), stream.GetName())); len(owners) > 0 {
+		member = owners[0]
+	} else {
+		var err error
+		if member, err = d.createMember(ctx, dstDir.GetPath()); err != nil {
+			return err
+		}
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, dstDir.GetPath()))
+	if err != nil {
+		return err
+	}
+	return op.Put(ctx, storage, actualPath, stream, up)
+}
+
+func (d *Union) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	res := &model.StorageDetails{}
+	found := false
+	for _, member := range d.members {
+		details, err := d.details(ctx, member)
+		if err != nil {
+			continue
+		}
+		found = true
+		res.TotalSpace += details.TotalSpace
+		res.FreeSpace += details.FreeSpace
+	}
+	if !found {
+		return nil, errs.NotImplement
+	}
+	return res, nil
+}
+
+var _ driver.Driver = (*Union)(nil)
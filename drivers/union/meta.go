@@ -0,0 +1,25 @@
+package union
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	Paths        string `json:"paths" required:"true" type:"text" help:"one alist path per line, the order is used by first_found"`
+	CreatePolicy string `json:"create_policy" type:"select" options:"first_found,most_free_space,least_used,round_robin" default:"first_found" help:"which member new files and folders are created in, most_free_space and least_used only rank members reporting their space (local, union, mirror) and need at least one of them"`
+}
+
+var config = driver.Config{
+	Name:             "Union",
+	LocalSort:        true,
+	NoCache:          true,
+	DefaultRoot:      "/",
+	ProxyRangeOption: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Union{}
+	})
+}
@@ -0,0 +1,196 @@
+package union
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func (d *Union) get(ctx context.Context, member, path string) (model.Obj, error) {
+	return fs.Get(ctx, stdpath.Join(member, path), &fs.GetArgs{NoLog: true})
+}
+
+func (d *Union) list(ctx context.Context, member, path string, refresh bool) ([]model.Obj, error) {
+	objs, err := fs.List(ctx, stdpath.Join(member, path), &fs.ListArgs{NoLog: true, Refresh: refresh})
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(objs, func(obj model.Obj) (model.Obj, error) {
+		objRes := model.Object{
+			Path:     stdpath.Join(path, obj.GetName()),
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			IsFolder: obj.IsDir(),
+			HashInfo: obj.GetHash(),
+		}
+		thumb, ok := model.GetThumb(obj)
+		if !ok {
+			return &objRes, nil
+		}
+		return &model.ObjThumb{
+			Object: objRes,
+			Thumbnail: model.Thumbnail{
+				Thumbnail: thumb,
+			},
+		}, nil
+	})
+}
+
+func (d *Union) link(ctx context.Context, member, path string, args model.LinkArgs) (*model.Link, error) {
+	reqPath := stdpath.Join(member, path)
+	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true}); err != nil {
+		return nil, err
+	}
+	if common.ShouldProxy(storage, stdpath.Base(path)) {
+		link := &model.Link{
+			URL: fmt.Sprintf("%s/p%s?sign=%s",
+				common.GetApiUrl(args.HttpReq),
+				utils.EncodePath(reqPath, true),
+				sign.Sign(reqPath)),
+		}
+		if args.HttpReq != nil && d.ProxyRange {
+			link.RangeReadCloser = common.NoProxyRange
+		}
+		return link, nil
+	}
+	link, _, err := fs.Link(ctx, reqPath, args)
+	return link, err
+}
+
+func (d *Union) details(ctx context.Context, member string) (*model.StorageDetails, error) {
+	storage, _, err := op.GetStorageAndActualPath(member)
+	if err != nil {
+		return nil, err
+	}
+	return op.GetStorageDetails(ctx, storage)
+}
+
+// owners returns the members where the object exists
+func (d *Union) owners(ctx context.Context, path string) []string {
+	var res []string
+	for _, member := range d.members {
+		if _, err := d.get(ctx, member, path); err == nil {
+			res = append(res, member)
+		}
+	}
+	return res
+}
+
+func (d *Union) forEachOwner(ctx context.Context, path string, f func(member string) error) error {
+	owners := d.owners(ctx, path)
+	if len(owners) == 0 {
+		return errs.ObjectNotFound
+	}
+	for _, member := range owners {
+		if err := f(member); err != nil {
+			return errors.WithMessagef(err, "failed in member [%s]", member)
+		}
+	}
+	return nil
+}
+
+// moveOrCopy moves or copies the object to dstDir of the same member, dstDir is created if not exists
+func (d *Union) moveOrCopy(ctx context.Context, member, srcPath, dstDir string, isCopy bool) error {
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, srcPath))
+	if err != nil {
+		return err
+	}
+	dstStorage, dstActualPath, err := op.GetStorageAndActualPath(stdpath.Join(member, dstDir))
+	if err != nil {
+		return err
+	}
+	if srcStorage.GetStorage() != dstStorage.GetStorage() {
+		return errors.WithStack(errs.MoveBetweenTwoStorages)
+	}
+	if err = op.MakeDir(ctx, dstStorage, dstActualPath); err != nil {
+		return err
+	}
+	if isCopy {
+		return op.Copy(ctx, srcStorage, srcActualPath, dstActualPath)
+	}
+	return op.Move(ctx, srcStorage, srcActualPath, dstActualPath)
+}
+
+// checkCreatePolicy rejects the policies by space if no member is able to report it,
+// members not mounted yet are not known, so they are let pass
+func (d *Union) checkCreatePolicy() error {
+	if d.CreatePolicy != "most_free_space" && d.CreatePolicy != "least_used" {
+		return nil
+	}
+	for _, member := range d.members {
+		storage, _, err := op.GetStorageAndActualPath(member)
+		if err != nil {
+			return nil
+		}
+		if _, ok := storage.(driver.WithDetails); ok {
+			return nil
+		}
+	}
+	return errors.Errorf("create policy %s needs a member reporting its space", d.CreatePolicy)
+}
+
+// createMember chooses the member to create new objects in by the create policy,
+// the policies by space only rank the members reporting it and fall back to round_robin if none does
+func (d *Union) createMember(ctx context.Context, parentPath string) (string, error) {
+	var candidates []string
+	for _, member := range d.members {
+		storage, _, err := op.GetStorageAndActualPath(member)
+		if err != nil || storage.Config().NoUpload {
+			continue
+		}
+		candidates = append(candidates, member)
+	}
+	if len(candidates) == 0 {
+		return "", errors.WithStack(errs.UploadNotSupported)
+	}
+	switch d.CreatePolicy {
+	case "most_free_space", "least_used":
+		var best string
+		var bestValue int64
+		for _, member := range candidates {
+			details, err := d.details(ctx, member)
+			if err != nil {
+				continue
+			}
+			value := details.FreeSpace
+			if d.CreatePolicy == "least_used" {
+				value = -details.UsedSpace()
+			}
+			if best == "" || value > bestValue {
+				best, bestValue = member, value
+			}
+		}
+		if best != "" {
+			return best, nil
+		}
+		log.Warnf("[union] no member of [%s] reports its space, create policy %s falls back to round_robin", d.MountPath, d.CreatePolicy)
+		fallthrough
+	case "round_robin":
+		i := atomic.AddUint32(&d.rrIndex, 1)
+		return candidates[int(i)%len(candidates)], nil
+	}
+	// first_found prefers the member where the parent dir exists
+	for _, member := range candidates {
+		if _, err := d.get(ctx, member, parentPath); err == nil {
+			return member, nil
+		}
+	}
+	return candidates[0], nil
+}
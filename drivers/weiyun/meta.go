@@ -15,6 +15,7 @@ type Addition struct {
 
 var config = driver.Config{
 	Name:              "WeiYun",
+	NeedStore:         true,
 	LocalSort:         false,
 	OnlyProxy:         true,
 	CheckStatus:       true,
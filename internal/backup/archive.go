@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// the archive is gzipped json, the encrypted one is
+// magic | salt | nonce | AES-256-GCM sealed gzipped json
+var magic = []byte("ALISTBAK")
+
+const saltSize = 16
+
+func deriveKey(password string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(password), salt, 1<<15, 8, 1, 32)
+	return key, errors.WithStack(err)
+}
+
+// Marshal encodes the backup into an archive, which is encrypted if password is not empty
+func Marshal(b *Backup, password string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := utils.Json.NewEncoder(w).Encode(b); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if password == "" {
+		return buf.Bytes(), nil
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := newGCM(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := append(append(append([]byte{}, magic...), salt...), nonce...)
+	return gcm.Seal(res, nonce, buf.Bytes(), magic), nil
+}
+
+// Unmarshal decodes the archive, password is required if it's encrypted
+func Unmarshal(data []byte, password string) (*Backup, error) {
+	if bytes.HasPrefix(data, magic) {
+		if password == "" {
+			return nil, errors.New("the backup is encrypted, password is required")
+		}
+		data = data[len(magic):]
+		if len(data) < saltSize {
+			return nil, errors.New("broken backup")
+		}
+		gcm, err := newGCM(password, data[:saltSize])
+		if err != nil {
+			return nil, err
+		}
+		data = data[saltSize:]
+		if len(data) < gcm.NonceSize() {
+			return nil, errors.New("broken backup")
+		}
+		data, err = gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], magic)
+		if err != nil {
+			return nil, errors.New("wrong password or broken backup")
+		}
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid backup")
+	}
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid backup")
+	}
+	var b Backup
+	if err = utils.Json.Unmarshal(raw, &b); err != nil {
+		return nil, errors.Wrap(err, "invalid backup")
+	}
+	if b.Version > Version {
+		return nil, errors.Errorf("backup version %d is newer than supported %d, please upgrade alist", b.Version, Version)
+	}
+	return &b, nil
+}
+
+func newGCM(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	return gcm, errors.WithStack(err)
+}
@@ -0,0 +1,42 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestMarshal(t *testing.T) {
+	b := &Backup{
+		Version: Version,
+		Parts:   []string{PartUsers},
+		Users: []User{{
+			User:    model.User{Username: "alice"},
+			PwdHash: "hash",
+			Salt:    "salt",
+		}},
+	}
+	for _, password := range []string{"", "secret"} {
+		data, err := Marshal(b, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := Unmarshal(data, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Users) != 1 || res.Users[0].Username != "alice" || res.Users[0].PwdHash != "hash" {
+			t.Errorf("unexpected users after round trip: %+v", res.Users)
+		}
+	}
+	data, err := Marshal(b, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Unmarshal(data, "wrong"); err == nil {
+		t.Error("expect error with wrong password")
+	}
+	if _, err = Unmarshal(data, ""); err == nil {
+		t.Error("expect error without password")
+	}
+}
@@ -0,0 +1,146 @@
+package backup
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// Version is increased when the format of Backup changes incompatibly
+const Version = 1
+
+const (
+	PartStorages        = "storages"
+	PartUsers           = "users"
+	PartMetas           = "metas"
+	PartSettings        = "settings"
+	PartOfflineDownload = "offline_download"
+)
+
+var AllParts = []string{PartStorages, PartUsers, PartMetas, PartSettings, PartOfflineDownload}
+
+// User carries the credentials which are hidden in the json of model.User
+type User struct {
+	model.User
+	PwdHash   string `json:"pwd_hash"`
+	PwdTS     int64  `json:"pwd_ts"`
+	Salt      string `json:"salt"`
+	OtpSecret string `json:"otp_secret"`
+	Authn     string `json:"authn"`
+}
+
+type SSHKey struct {
+	model.SSHPublicKey
+	Username string `json:"username"`
+	KeyStr   string `json:"key_str"`
+}
+
+type Backup struct {
+	Version         int                 `json:"version"`
+	AlistVersion    string              `json:"alist_version"`
+	CreatedAt       time.Time           `json:"created_at"`
+	Parts           []string            `json:"parts"`
+	Storages        []model.Storage     `json:"storages,omitempty"`
+	Users           []User              `json:"users,omitempty"`
+	SSHKeys         []SSHKey            `json:"ssh_keys,omitempty"`
+	Metas           []model.Meta        `json:"metas,omitempty"`
+	Settings        []model.SettingItem `json:"settings,omitempty"`
+	OfflineDownload []model.SettingItem `json:"offline_download,omitempty"`
+}
+
+func (b *Backup) has(part string) bool {
+	for _, p := range b.Parts {
+		if p == part {
+			return true
+		}
+	}
+	return false
+}
+
+func checkParts(parts []string) ([]string, error) {
+	if len(parts) == 0 {
+		return AllParts, nil
+	}
+	for _, p := range parts {
+		valid := false
+		for _, a := range AllParts {
+			valid = valid || p == a
+		}
+		if !valid {
+			return nil, errors.Errorf("unknown part: %s", p)
+		}
+	}
+	return parts, nil
+}
+
+// Export collects the parts of the configuration, all parts if parts is empty
+func Export(parts []string) (*Backup, error) {
+	parts, err := checkParts(parts)
+	if err != nil {
+		return nil, err
+	}
+	b := &Backup{
+		Version:      Version,
+		AlistVersion: conf.Version,
+		CreatedAt:    time.Now(),
+		Parts:        parts,
+	}
+	if b.has(PartStorages) {
+		if b.Storages, _, err = db.GetStorages(1, -1); err != nil {
+			return nil, err
+		}
+	}
+	if b.has(PartUsers) {
+		users, _, err := db.GetUsers(1, -1)
+		if err != nil {
+			return nil, err
+		}
+		names := make(map[uint]string, len(users))
+		for _, u := range users {
+			names[u.ID] = u.Username
+			b.Users = append(b.Users, User{
+				User:      u,
+				PwdHash:   u.PwdHash,
+				PwdTS:     u.PwdTS,
+				Salt:      u.Salt,
+				OtpSecret: u.OtpSecret,
+				Authn:     u.Authn,
+			})
+		}
+		keys, _, err := db.GetSSHPublicKeys(1, -1)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			b.SSHKeys = append(b.SSHKeys, SSHKey{SSHPublicKey: k, Username: names[k.UserId], KeyStr: k.KeyStr})
+		}
+	}
+	if b.has(PartMetas) {
+		if b.Metas, _, err = db.GetMetas(1, -1); err != nil {
+			return nil, err
+		}
+	}
+	if b.has(PartSettings) || b.has(PartOfflineDownload) {
+		items, err := db.GetSettingItems()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			// version and the like are maintained by alist itself
+			if item.Flag == model.READONLY || item.Flag == model.DEPRECATED {
+				continue
+			}
+			if item.Group == model.OFFLINE_DOWNLOAD {
+				if b.has(PartOfflineDownload) {
+					b.OfflineDownload = append(b.OfflineDownload, item)
+				}
+			} else if b.has(PartSettings) {
+				b.Settings = append(b.Settings, item)
+			}
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,301 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ModeMerge creates the items in the backup or overwrites the ones with the same key,
+	// the other existing items are kept
+	ModeMerge = "merge"
+	// ModeReplace also deletes the existing items which are not in the backup,
+	// settings, the admin and the guest are never deleted
+	ModeReplace = "replace"
+)
+
+type ImportOptions struct {
+	Parts []string
+	Mode  string
+	// Live loads the imported storages into the running server,
+	// otherwise they are only written to the database
+	Live bool
+}
+
+type ImportResult struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Deleted []string `json:"deleted"`
+	Errors  []string `json:"errors"`
+}
+
+const (
+	created = iota
+	updated
+	deleted
+)
+
+func (r *ImportResult) add(part, key string, action int, err error) {
+	item := fmt.Sprintf("%s: %s", part, key)
+	switch {
+	case err != nil:
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %s", item, err))
+	case action == created:
+		r.Created = append(r.Created, item)
+	case action == updated:
+		r.Updated = append(r.Updated, item)
+	case action == deleted:
+		r.Deleted = append(r.Deleted, item)
+	}
+}
+
+// Validate checks the backup against the current driver registry
+func Validate(b *Backup) error {
+	var errs []error
+	for _, s := range b.Storages {
+		if s.MountPath == "" {
+			errs = append(errs, errors.New("storage with empty mount path"))
+			continue
+		}
+		driverNew, err := op.GetDriver(s.Driver)
+		if err != nil {
+			errs = append(errs, errors.WithMessagef(err, "storage [%s]", s.MountPath))
+			continue
+		}
+		if err = utils.Json.UnmarshalFromString(s.Addition, driverNew().GetAddition()); err != nil {
+			errs = append(errs, errors.Wrapf(err, "storage [%s] has incompatible addition for driver [%s]", s.MountPath, s.Driver))
+		}
+	}
+	for _, u := range b.Users {
+		if u.Username == "" {
+			errs = append(errs, errors.New("user with empty username"))
+		}
+	}
+	for _, m := range b.Metas {
+		if m.Path == "" {
+			errs = append(errs, errors.New("meta with empty path"))
+		}
+	}
+	return utils.MergeErrors(errs...)
+}
+
+// Import writes the parts of the backup, all parts in it if opts.Parts is empty
+func Import(ctx context.Context, b *Backup, opts ImportOptions) (*ImportResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = ModeMerge
+	}
+	if opts.Mode != ModeMerge && opts.Mode != ModeReplace {
+		return nil, errors.Errorf("unknown import mode: %s", opts.Mode)
+	}
+	parts := opts.Parts
+	if len(parts) == 0 {
+		parts = b.Parts
+	}
+	if _, err := checkParts(parts); err != nil {
+		return nil, err
+	}
+	for _, p := range parts {
+		if !b.has(p) {
+			return nil, errors.Errorf("part [%s] is not in the backup", p)
+		}
+	}
+	if err := Validate(b); err != nil {
+		return nil, err
+	}
+	replace := opts.Mode == ModeReplace
+	res := &ImportResult{}
+	selected := &Backup{Parts: parts}
+	if selected.has(PartStorages) {
+		importStorages(ctx, res, b.Storages, replace, opts.Live)
+	}
+	if selected.has(PartUsers) {
+		importUsers(res, b.Users, b.SSHKeys, replace)
+	}
+	if selected.has(PartMetas) {
+		importMetas(res, b.Metas, replace)
+	}
+	if selected.has(PartSettings) {
+		importSettings(res, PartSettings, b.Settings)
+	}
+	if selected.has(PartOfflineDownload) {
+		importSettings(res, PartOfflineDownload, b.OfflineDownload)
+	}
+	return res, nil
+}
+
+func importStorages(ctx context.Context, res *ImportResult, storages []model.Storage, replace, live bool) {
+	keep := make(map[string]struct{})
+	for _, s := range storages {
+		s.MountPath = utils.FixAndCleanPath(s.MountPath)
+		keep[s.MountPath] = struct{}{}
+		old, err := db.GetStorageByMountPath(s.MountPath)
+		if err != nil {
+			s.ID = 0
+			if live {
+				_, err = op.CreateStorage(ctx, s)
+			} else {
+				err = db.CreateStorage(&s)
+			}
+			res.add(PartStorages, s.MountPath, created, err)
+			continue
+		}
+		s.ID = old.ID
+		if live {
+			err = op.UpdateStorage(ctx, s)
+		} else {
+			err = db.UpdateStorage(&s)
+		}
+		res.add(PartStorages, s.MountPath, updated, err)
+	}
+	if !replace {
+		return
+	}
+	olds, _, err := db.GetStorages(1, -1)
+	if err != nil {
+		res.add(PartStorages, "*", deleted, err)
+		return
+	}
+	for _, s := range olds {
+		if _, ok := keep[s.MountPath]; ok {
+			continue
+		}
+		if live {
+			err = op.DeleteStorageById(ctx, s.ID)
+		} else {
+			err = db.DeleteStorageById(s.ID)
+		}
+		res.add(PartStorages, s.MountPath, deleted, err)
+	}
+}
+
+// findUser matches the user by name, the admin and the guest are matched by role
+// since there is only one of each
+func findUser(u *model.User) (*model.User, error) {
+	if u.IsAdmin() || u.IsGuest() {
+		return db.GetUserByRole(u.Role)
+	}
+	return db.GetUserByName(u.Username)
+}
+
+func importUsers(res *ImportResult, users []User, keys []SSHKey, replace bool) {
+	keep := make(map[uint]struct{})
+	for _, u := range users {
+		user := u.User
+		user.PwdHash, user.PwdTS, user.Salt = u.PwdHash, u.PwdTS, u.Salt
+		user.OtpSecret, user.Authn = u.OtpSecret, u.Authn
+		user.Password = ""
+		old, err := findUser(&user)
+		if err != nil {
+			user.ID = 0
+			err = op.CreateUser(&user)
+			res.add(PartUsers, user.Username, created, err)
+		} else {
+			user.ID, user.Role = old.ID, old.Role
+			err = op.UpdateUser(&user)
+			res.add(PartUsers, user.Username, updated, err)
+		}
+		if err == nil {
+			keep[user.ID] = struct{}{}
+		}
+	}
+	keepKeys := make(map[uint]struct{})
+	for _, k := range keys {
+		name := k.Username + "/" + k.Title
+		user, err := db.GetUserByName(k.Username)
+		if err != nil {
+			res.add("ssh_keys", name, created, err)
+			continue
+		}
+		key := k.SSHPublicKey
+		key.UserId, key.KeyStr = user.ID, k.KeyStr
+		old, err := db.GetSSHPublicKeyByUserTitle(user.ID, key.Title)
+		if err != nil {
+			key.ID = 0
+			err = db.CreateSSHPublicKey(&key)
+			res.add("ssh_keys", name, created, err)
+		} else {
+			key.ID = old.ID
+			err = db.UpdateSSHPublicKey(&key)
+			res.add("ssh_keys", name, updated, err)
+		}
+		if err == nil {
+			keepKeys[key.ID] = struct{}{}
+		}
+	}
+	if !replace {
+		return
+	}
+	olds, _, err := db.GetUsers(1, -1)
+	if err != nil {
+		res.add(PartUsers, "*", deleted, err)
+		return
+	}
+	for _, u := range olds {
+		if _, ok := keep[u.ID]; ok || u.IsAdmin() || u.IsGuest() {
+			continue
+		}
+		res.add(PartUsers, u.Username, deleted, op.DeleteUserById(u.ID))
+	}
+	oldKeys, _, err := db.GetSSHPublicKeys(1, -1)
+	if err != nil {
+		res.add("ssh_keys", "*", deleted, err)
+		return
+	}
+	for _, k := range oldKeys {
+		if _, ok := keepKeys[k.ID]; ok {
+			continue
+		}
+		// only the keys of the imported users are replaced
+		if _, ok := keep[k.UserId]; ok {
+			res.add("ssh_keys", k.Title, deleted, db.DeleteSSHPublicKeyById(k.ID))
+		}
+	}
+}
+
+func importMetas(res *ImportResult, metas []model.Meta, replace bool) {
+	keep := make(map[string]struct{})
+	for _, m := range metas {
+		m.Path = utils.FixAndCleanPath(m.Path)
+		keep[m.Path] = struct{}{}
+		old, err := db.GetMetaByPath(m.Path)
+		if err != nil {
+			m.ID = 0
+			res.add(PartMetas, m.Path, created, op.CreateMeta(&m))
+			continue
+		}
+		m.ID = old.ID
+		res.add(PartMetas, m.Path, updated, op.UpdateMeta(&m))
+	}
+	if !replace {
+		return
+	}
+	olds, _, err := db.GetMetas(1, -1)
+	if err != nil {
+		res.add(PartMetas, "*", deleted, err)
+		return
+	}
+	for _, m := range olds {
+		if _, ok := keep[m.Path]; !ok {
+			res.add(PartMetas, m.Path, deleted, op.DeleteMetaById(m.ID))
+		}
+	}
+}
+
+// importSettings restores the values only, the others are defined by the current version,
+// unknown keys are from other versions and ignored
+func importSettings(res *ImportResult, part string, items []model.SettingItem) {
+	for _, item := range items {
+		old, err := db.GetSettingItemByKey(item.Key)
+		if err != nil || old.Value == item.Value {
+			continue
+		}
+		old.Value = item.Value
+		res.add(part, item.Key, updated, op.SaveSettingItem(old))
+	}
+}
@@ -7,10 +7,33 @@ import (
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/provision"
 	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
+// Provision reconciles the DB with the declarative file if configured
+func Provision() {
+	if conf.Conf.Provision == "" {
+		return
+	}
+	f, err := provision.Load(conf.Conf.Provision)
+	if err != nil {
+		utils.Log.Fatalf("failed load provision file: %+v", err)
+	}
+	changes, err := provision.Plan(f)
+	if err != nil {
+		utils.Log.Fatalf("failed plan provision: %+v", err)
+	}
+	for _, c := range changes {
+		utils.Log.Infof("provision: %s", c)
+	}
+	if err = provision.Apply(changes); err != nil {
+		utils.Log.Errorf("failed apply provision: %+v", err)
+	}
+}
+
 func LoadStorages() {
+	Provision()
 	storages, err := db.GetEnabledStorages()
 	if err != nil {
 		utils.Log.Fatalf("failed get enabled storages: %+v", err)
@@ -11,6 +11,10 @@ import (
 func InitTaskManager() {
 	fs.UploadTaskManager = tache.NewManager[*fs.UploadTask](tache.WithWorks(conf.Conf.Tasks.Upload.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Upload.MaxRetry)) //upload will not support persist
 	fs.CopyTaskManager = tache.NewManager[*fs.CopyTask](tache.WithWorks(conf.Conf.Tasks.Copy.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("copy", conf.Conf.Tasks.Copy.TaskPersistant), db.UpdateTaskDataFunc("copy", conf.Conf.Tasks.Copy.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Copy.MaxRetry))
+	fs.SyncTaskManager = tache.NewManager[*fs.SyncTask](tache.WithWorks(conf.Conf.Tasks.Sync.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("sync", conf.Conf.Tasks.Sync.TaskPersistant), db.UpdateTaskDataFunc("sync", conf.Conf.Tasks.Sync.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Sync.MaxRetry))
+	fs.DuplicateTaskManager = tache.NewManager[*fs.DuplicateTask](tache.WithWorks(conf.Conf.Tasks.Duplicate.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Duplicate.MaxRetry)) //duplicate scan will not support persist
+	fs.DuTaskManager = tache.NewManager[*fs.DuTask](tache.WithWorks(conf.Conf.Tasks.Du.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Du.MaxRetry))
+	fs.OtherTaskManager = tache.NewManager[*fs.OtherTask](tache.WithWorks(conf.Conf.Tasks.Other.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Other.MaxRetry)) //the run func of drivers can't be persisted
 	tool.DownloadTaskManager = tache.NewManager[*tool.DownloadTask](tache.WithWorks(conf.Conf.Tasks.Download.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("download", conf.Conf.Tasks.Download.TaskPersistant), db.UpdateTaskDataFunc("download", conf.Conf.Tasks.Download.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Download.MaxRetry))
 	tool.TransferTaskManager = tache.NewManager[*tool.TransferTask](tache.WithWorks(conf.Conf.Tasks.Transfer.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("transfer", conf.Conf.Tasks.Transfer.TaskPersistant), db.UpdateTaskDataFunc("transfer", conf.Conf.Tasks.Transfer.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Transfer.MaxRetry))
 	if len(tool.TransferTaskManager.GetAll()) == 0 { //prevent offline downloaded files from being deleted
@@ -0,0 +1,21 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+var purgeCron *cron.Cron
+
+// InitTrash starts purging expired objects in trash, file versions and tus uploads periodically
+func InitTrash() {
+	purgeCron = cron.NewCron(time.Hour)
+	purgeCron.Do(func() {
+		fs.PurgeExpiredTrash(context.Background())
+		fs.PurgeExpiredVersions(context.Background())
+		fs.PurgeExpiredTusUploads()
+	})
+}
@@ -53,10 +53,14 @@ type TaskConfig struct {
 }
 
 type TasksConfig struct {
-	Download TaskConfig `json:"download" envPrefix:"DOWNLOAD_"`
-	Transfer TaskConfig `json:"transfer" envPrefix:"TRANSFER_"`
-	Upload   TaskConfig `json:"upload" envPrefix:"UPLOAD_"`
-	Copy     TaskConfig `json:"copy" envPrefix:"COPY_"`
+	Download  TaskConfig `json:"download" envPrefix:"DOWNLOAD_"`
+	Transfer  TaskConfig `json:"transfer" envPrefix:"TRANSFER_"`
+	Upload    TaskConfig `json:"upload" envPrefix:"UPLOAD_"`
+	Copy      TaskConfig `json:"copy" envPrefix:"COPY_"`
+	Sync      TaskConfig `json:"sync" envPrefix:"SYNC_"`
+	Duplicate TaskConfig `json:"duplicate" envPrefix:"DUPLICATE_"`
+	Du        TaskConfig `json:"du" envPrefix:"DU_"`
+	Other     TaskConfig `json:"other" envPrefix:"OTHER_"`
 }
 
 type Cors struct {
@@ -89,6 +93,11 @@ type SFTP struct {
 	Listen string `json:"listen" env:"LISTEN"`
 }
 
+type Metrics struct {
+	Enable bool   `json:"enable" env:"ENABLE"`
+	Token  string `json:"token" env:"TOKEN"`
+}
+
 type Config struct {
 	Force                 bool        `json:"force" env:"FORCE"`
 	SiteURL               string      `json:"site_url" env:"SITE_URL"`
@@ -110,7 +119,9 @@ type Config struct {
 	S3                    S3          `json:"s3" envPrefix:"S3_"`
 	FTP                   FTP         `json:"ftp" envPrefix:"FTP_"`
 	SFTP                  SFTP        `json:"sftp" envPrefix:"SFTP_"`
+	Metrics               Metrics     `json:"metrics" envPrefix:"METRICS_"`
 	LastLaunchedVersion   string      `json:"last_launched_version"`
+	Provision             string      `json:"provision" env:"PROVISION"`
 }
 
 func DefaultConfig() *Config {
@@ -169,6 +180,19 @@ func DefaultConfig() *Config {
 				MaxRetry: 2,
 				// TaskPersistant: true,
 			},
+			Sync: TaskConfig{
+				Workers:  2,
+				MaxRetry: 2,
+			},
+			Duplicate: TaskConfig{
+				Workers: 1,
+			},
+			Du: TaskConfig{
+				Workers: 2,
+			},
+			Other: TaskConfig{
+				Workers: 2,
+			},
 		},
 		Cors: Cors{
 			AllowOrigins: []string{"*"},
@@ -196,6 +220,10 @@ func DefaultConfig() *Config {
 			Enable: false,
 			Listen: ":5222",
 		},
+		Metrics: Metrics{
+			Enable: false,
+			Token:  "",
+		},
 		LastLaunchedVersion: "",
 	}
 }
@@ -43,6 +43,8 @@ const (
 	ForwardDirectLinkParams = "forward_direct_link_params"
 	IgnoreDirectLinkParams  = "ignore_direct_link_params"
 	WebauthnLoginEnabled    = "webauthn_login_enabled"
+	StorageHealthCheck      = "storage_health_check_interval"
+	ShowDirSize             = "show_dir_size"
 
 	// index
 	SearchIndex     = "search_index"
@@ -67,6 +69,15 @@ const (
 	// thunder
 	ThunderTempDir = "thunder_temp_dir"
 
+	// bittorrent
+	BitTorrentListenPort = "bittorrent_listen_port"
+	BitTorrentSeedRatio  = "bittorrent_seed_ratio"
+	BitTorrentSeedtime   = "bittorrent_seedtime"
+
+	// simple http
+	SimpleHttpConcurrency = "simple_http_concurrency"
+	SimpleHttpStream      = "simple_http_stream"
+
 	// single
 	Token         = "token"
 	IndexProgress = "index_progress"
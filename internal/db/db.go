@@ -12,7 +12,7 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey))
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey), new(model.Webhook), new(model.WebhookDelivery), new(model.TrashItem), new(model.FileVersion), new(model.UploadRecord), new(model.TusUpload), new(model.Subscription), new(model.SubscriptionItem), new(model.SyncJob), new(model.DuplicateGroup), new(model.DuplicateFile), new(model.DirSize))
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
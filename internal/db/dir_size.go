@@ -0,0 +1,60 @@
+package db
+
+import (
+	"fmt"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ReplaceDirSizes replaces the stored sizes of root and all dirs under it,
+// the sizes of the parents of root are stale now, so they are deleted
+func ReplaceDirSizes(root string, sizes []model.DirSize) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteDirSizes(tx, root); err != nil {
+			return err
+		}
+		if len(sizes) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(sizes, 100).Error
+	}))
+}
+
+// DeleteDirSizes deletes the stored sizes changed by a write at path,
+// that's the ones of path, of the dirs under it and of all its parents
+func DeleteDirSizes(path string) error {
+	return errors.Wrapf(deleteDirSizes(db, path), "failed delete dir sizes")
+}
+
+func deleteDirSizes(tx *gorm.DB, path string) error {
+	var parents []string
+	for p := path; p != "/"; {
+		p = stdpath.Dir(p)
+		parents = append(parents, p)
+	}
+	cond, pattern := subPathCond(path)
+	if len(parents) == 0 {
+		return tx.Where(fmt.Sprintf("%s = ? OR %s", columnName("path"), cond), path, pattern).Delete(&model.DirSize{}).Error
+	}
+	return tx.Where(fmt.Sprintf("%s = ? OR %s IN ? OR %s", columnName("path"), columnName("path"), cond),
+		path, parents, pattern).Delete(&model.DirSize{}).Error
+}
+
+func GetDirSize(path string) (*model.DirSize, error) {
+	var size model.DirSize
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("path")), path).First(&size).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get dir size")
+	}
+	return &size, nil
+}
+
+func GetDirSizes(paths []string) ([]model.DirSize, error) {
+	var sizes []model.DirSize
+	if err := db.Where(fmt.Sprintf("%s IN ?", columnName("path")), paths).Find(&sizes).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return sizes, nil
+}
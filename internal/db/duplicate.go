@@ -0,0 +1,87 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ReplaceDuplicateGroups replaces all stored groups with the groups of a new scan
+func ReplaceDuplicateGroups(groups []model.DuplicateGroup) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.DuplicateFile{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&model.DuplicateGroup{}).Error; err != nil {
+			return err
+		}
+		for i := range groups {
+			if err := tx.Create(&groups[i]).Error; err != nil {
+				return err
+			}
+			for j := range groups[i].Files {
+				groups[i].Files[j].GroupID = groups[i].ID
+			}
+			if err := tx.Create(&groups[i].Files).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func GetDuplicateGroups(pageIndex, pageSize int) (groups []model.DuplicateGroup, count int64, err error) {
+	groupDB := db.Model(&model.DuplicateGroup{})
+	if err = groupDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get duplicate groups count")
+	}
+	if err = groupDB.Order(fmt.Sprintf("%s DESC", columnName("size"))).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&groups).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find duplicate groups")
+	}
+	ids := make([]uint, len(groups))
+	for i := range groups {
+		ids[i] = groups[i].ID
+	}
+	var files []model.DuplicateFile
+	if err = db.Where(fmt.Sprintf("%s IN ?", columnName("group_id")), ids).Order(columnName("id")).Find(&files).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find duplicate files")
+	}
+	for i := range groups {
+		for _, f := range files {
+			if f.GroupID == groups[i].ID {
+				groups[i].Files = append(groups[i].Files, f)
+			}
+		}
+	}
+	return groups, count, nil
+}
+
+func GetDuplicateGroupById(id uint) (*model.DuplicateGroup, error) {
+	var g model.DuplicateGroup
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get duplicate group")
+	}
+	return &g, nil
+}
+
+func GetDuplicateFilesByIds(ids []uint) ([]model.DuplicateFile, error) {
+	var files []model.DuplicateFile
+	if err := db.Where(fmt.Sprintf("%s IN ?", columnName("id")), ids).Find(&files).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return files, nil
+}
+
+func GetDuplicateFilesByGroupId(groupId uint) ([]model.DuplicateFile, error) {
+	var files []model.DuplicateFile
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("group_id")), groupId).Order(columnName("id")).Find(&files).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return files, nil
+}
+
+func UpdateDuplicateFile(f *model.DuplicateFile) error {
+	return errors.WithStack(db.Save(f).Error)
+}
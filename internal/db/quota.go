@@ -0,0 +1,62 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ReplaceUploadRecord saves the record of the file, the record of the file it overwrote is replaced
+func ReplaceUploadRecord(r *model.UploadRecord) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(fmt.Sprintf("%s = ?", columnName("path")), r.Path).Delete(&model.UploadRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(r).Error
+	}))
+}
+
+// GetUserUploadedBytes get total bytes uploaded by the user
+func GetUserUploadedBytes(userId uint) (int64, error) {
+	var total int64
+	err := db.Model(&model.UploadRecord{}).Select("COALESCE(SUM(size), 0)").
+		Where(fmt.Sprintf("%s = ?", columnName("user_id")), userId).Scan(&total).Error
+	return total, errors.Wrapf(err, "failed get uploaded bytes of user")
+}
+
+// GetPathUploadedBytes get total bytes uploaded into the path and its sub dirs
+func GetPathUploadedBytes(path string) (int64, error) {
+	var total int64
+	dbPath := db.Model(&model.UploadRecord{}).Select("COALESCE(SUM(size), 0)")
+	if path != "/" {
+		dbPath = dbPath.Where(subPathCond(path))
+	}
+	err := dbPath.Scan(&total).Error
+	return total, errors.Wrapf(err, "failed get uploaded bytes of path")
+}
+
+// DeleteUploadRecords deletes the records of the file, or of the files in the dir, after it's removed
+func DeleteUploadRecords(path string) error {
+	cond, pattern := subPathCond(path)
+	err := db.Where(fmt.Sprintf("%s = ? OR %s", columnName("path"), cond), path, pattern).Delete(&model.UploadRecord{}).Error
+	return errors.Wrapf(err, "failed delete upload records")
+}
+
+// MoveUploadRecords updates the paths of the records of the file, or of the files in the dir, after it's moved or renamed
+func MoveUploadRecords(oldPath, newPath string) error {
+	var records []model.UploadRecord
+	cond, pattern := subPathCond(oldPath)
+	if err := db.Where(fmt.Sprintf("%s = ? OR %s", columnName("path"), cond), oldPath, pattern).Find(&records).Error; err != nil {
+		return errors.Wrapf(err, "failed find upload records")
+	}
+	for i := range records {
+		path := newPath + strings.TrimPrefix(records[i].Path, oldPath)
+		if err := db.Model(&records[i]).Update("path", path).Error; err != nil {
+			return errors.Wrapf(err, "failed update upload record")
+		}
+	}
+	return nil
+}
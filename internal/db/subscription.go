@@ -0,0 +1,95 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetSubscriptionById(id uint) (*model.Subscription, error) {
+	var s model.Subscription
+	if err := db.First(&s, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get subscription")
+	}
+	return &s, nil
+}
+
+func CreateSubscription(s *model.Subscription) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func UpdateSubscription(s *model.Subscription) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+// UpdateSubscriptionCheck updates only the result of the last check, so the edits made meanwhile are kept
+func UpdateSubscriptionCheck(s *model.Subscription) error {
+	return errors.WithStack(db.Model(&model.Subscription{ID: s.ID}).Updates(map[string]interface{}{
+		columnName("last_check"): s.LastCheck,
+		columnName("last_error"): s.LastError,
+	}).Error)
+}
+
+func DeleteSubscriptionById(id uint) error {
+	if err := DeleteSubscriptionItems(id); err != nil {
+		return errors.WithMessage(err, "failed delete subscription items")
+	}
+	return errors.WithStack(db.Delete(&model.Subscription{}, id).Error)
+}
+
+func GetSubscriptions(pageIndex, pageSize int) (subscriptions []model.Subscription, count int64, err error) {
+	subscriptionDB := db.Model(&model.Subscription{})
+	if err = subscriptionDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get subscriptions count")
+	}
+	if err = subscriptionDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&subscriptions).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find subscriptions")
+	}
+	return subscriptions, count, nil
+}
+
+func GetEnabledSubscriptions() ([]model.Subscription, error) {
+	var subscriptions []model.Subscription
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("disabled")), false).Find(&subscriptions).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return subscriptions, nil
+}
+
+func GetSubscriptionItemById(id uint) (*model.SubscriptionItem, error) {
+	var item model.SubscriptionItem
+	if err := db.First(&item, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get subscription item")
+	}
+	return &item, nil
+}
+
+// GetSubscriptionGUIDs returns the guids of all items of the subscription
+func GetSubscriptionGUIDs(subscriptionId uint) ([]string, error) {
+	var guids []string
+	if err := db.Model(&model.SubscriptionItem{}).Where(fmt.Sprintf("%s = ?", columnName("subscription_id")), subscriptionId).
+		Pluck(columnName("guid"), &guids).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return guids, nil
+}
+
+func SaveSubscriptionItem(item *model.SubscriptionItem) error {
+	return errors.WithStack(db.Save(item).Error)
+}
+
+func GetSubscriptionItems(subscriptionId uint, pageIndex, pageSize int) (items []model.SubscriptionItem, count int64, err error) {
+	itemDB := db.Model(&model.SubscriptionItem{}).Where(fmt.Sprintf("%s = ?", columnName("subscription_id")), subscriptionId)
+	if err = itemDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get subscription items count")
+	}
+	if err = itemDB.Order(fmt.Sprintf("%s DESC", columnName("id"))).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find subscription items")
+	}
+	return items, count, nil
+}
+
+func DeleteSubscriptionItems(subscriptionId uint) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ?", columnName("subscription_id")), subscriptionId).Delete(&model.SubscriptionItem{}).Error)
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateTrashItem(t *model.TrashItem) error {
+	return errors.WithStack(db.Create(t).Error)
+}
+
+func GetTrashItemById(id uint) (*model.TrashItem, error) {
+	var t model.TrashItem
+	if err := db.First(&t, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get trash item")
+	}
+	return &t, nil
+}
+
+// GetTrashItems get trash items of the user, userId = 0 means all users
+func GetTrashItems(userId uint, pageIndex, pageSize int) (items []model.TrashItem, count int64, err error) {
+	trashDB := db.Model(&model.TrashItem{})
+	if userId != 0 {
+		trashDB = trashDB.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userId)
+	}
+	if err = trashDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get trash items count")
+	}
+	if err = trashDB.Order(fmt.Sprintf("%s DESC", columnName("id"))).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find trash items")
+	}
+	return items, count, nil
+}
+
+func GetTrashItemsBefore(t time.Time) ([]model.TrashItem, error) {
+	var items []model.TrashItem
+	if err := db.Where(fmt.Sprintf("%s < ?", columnName("removed_at")), t).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return items, nil
+}
+
+func DeleteTrashItemById(id uint) error {
+	return errors.WithStack(db.Delete(&model.TrashItem{}, id).Error)
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateTusUpload(u *model.TusUpload) error {
+	return errors.WithStack(db.Create(u).Error)
+}
+
+func GetTusUploadById(id string) (*model.TusUpload, error) {
+	var u model.TusUpload
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("id")), id).First(&u).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get tus upload")
+	}
+	return &u, nil
+}
+
+func UpdateTusUploadOffset(id string, offset int64) error {
+	return errors.WithStack(db.Model(&model.TusUpload{}).Where(fmt.Sprintf("%s = ?", columnName("id")), id).
+		Update("offset", offset).Error)
+}
+
+func GetTusUploadsBefore(t time.Time) ([]model.TusUpload, error) {
+	var uploads []model.TusUpload
+	if err := db.Where(fmt.Sprintf("%s < ?", columnName("updated_at")), t).Find(&uploads).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return uploads, nil
+}
+
+func DeleteTusUploadById(id string) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ?", columnName("id")), id).Delete(&model.TusUpload{}).Error)
+}
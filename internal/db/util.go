@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"gorm.io/gorm"
@@ -14,6 +15,14 @@ func columnName(name string) string {
 	return fmt.Sprintf("`%s`", name)
 }
 
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// subPathCond returns the condition matching the path column with the paths under dir,
+// the wildcards in dir are escaped
+func subPathCond(dir string) (string, string) {
+	return fmt.Sprintf("%s LIKE ? ESCAPE '!'", columnName("path")), likeEscaper.Replace(strings.TrimSuffix(dir, "/")) + "/%"
+}
+
 func addStorageOrder(db *gorm.DB) *gorm.DB {
 	return db.Order(fmt.Sprintf("%s, %s", columnName("order"), columnName("id")))
 }
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateFileVersion(v *model.FileVersion) error {
+	return errors.WithStack(db.Create(v).Error)
+}
+
+func GetFileVersionById(id uint) (*model.FileVersion, error) {
+	var v model.FileVersion
+	if err := db.First(&v, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get file version")
+	}
+	return &v, nil
+}
+
+// GetFileVersions get versions of the file, newest first
+func GetFileVersions(path string) ([]model.FileVersion, error) {
+	var versions []model.FileVersion
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("path")), path).
+		Order(fmt.Sprintf("%s DESC", columnName("id"))).Find(&versions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find file versions")
+	}
+	return versions, nil
+}
+
+func GetFileVersionsBefore(t time.Time) ([]model.FileVersion, error) {
+	var versions []model.FileVersion
+	if err := db.Where(fmt.Sprintf("%s < ?", columnName("created_at")), t).Find(&versions).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return versions, nil
+}
+
+// GetFileVersionsUnder get versions of the file, or of the files in the dir
+func GetFileVersionsUnder(path string) ([]model.FileVersion, error) {
+	var versions []model.FileVersion
+	cond, pattern := subPathCond(path)
+	if err := db.Where(fmt.Sprintf("%s = ? OR %s", columnName("path"), cond), path, pattern).Find(&versions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find file versions")
+	}
+	return versions, nil
+}
+
+// MoveFileVersions updates the paths of the versions of the file, or of the files in the dir, after it's moved or renamed
+func MoveFileVersions(oldPath, newPath string) error {
+	versions, err := GetFileVersionsUnder(oldPath)
+	if err != nil {
+		return err
+	}
+	for i := range versions {
+		path := newPath + strings.TrimPrefix(versions[i].Path, oldPath)
+		if err := db.Model(&versions[i]).Update("path", path).Error; err != nil {
+			return errors.Wrapf(err, "failed update file version")
+		}
+	}
+	return nil
+}
+
+func DeleteFileVersionById(id uint) error {
+	return errors.WithStack(db.Delete(&model.FileVersion{}, id).Error)
+}
@@ -13,6 +13,7 @@ type Config struct {
 	Alert             string `json:"alert"` //info,success,warning,danger
 	NoOverwriteUpload bool   `json:"-"`     // whether to support overwrite upload
 	ProxyRangeOption  bool   `json:"-"`
+	NeedStore         bool   `json:"-"` // whether Put always reads the whole file first, e.g. to hash it
 }
 
 func (c Config) MustProxy() bool {
@@ -29,6 +29,17 @@ type Other interface {
 	Other(ctx context.Context, args model.OtherArgs) (interface{}, error)
 }
 
+// WriteOther is implemented by drivers with Other methods that change data,
+// these methods need write permission
+type WriteOther interface {
+	IsWriteMethod(method string) bool
+}
+
+type WithDetails interface {
+	// GetDetails get the total and free space of the storage
+	GetDetails(ctx context.Context) (*model.StorageDetails, error)
+}
+
 type Reader interface {
 	// List files in the path
 	// if identify files by path, need to set ID with path,like path.Join(dir.GetID(), obj.GetName())
@@ -1,8 +1,12 @@
 package errs
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"syscall"
 
 	pkgerr "github.com/pkg/errors"
 )
@@ -19,6 +23,11 @@ var (
 	StorageNotFound  = errors.New("storage not found")
 	StreamIncomplete = errors.New("upload/download stream incomplete, possible network issue")
 	StreamPeekFail   = errors.New("StreamPeekFail")
+
+	// Unauthorized is returned by drivers when the credential is expired or invalid
+	Unauthorized = errors.New("unauthorized")
+	// ServerError is returned when the remote answers with a 5xx status
+	ServerError = errors.New("server error")
 )
 
 // NewErr wrap constant error with an extra message
@@ -31,6 +40,22 @@ func IsNotFoundError(err error) bool {
 	return errors.Is(pkgerr.Cause(err), ObjectNotFound) || errors.Is(pkgerr.Cause(err), StorageNotFound)
 }
 
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, Unauthorized)
+}
+
+// IsStorageFailure reports whether the error is a failure of the storage rather than of the
+// requested object: a transport error, failed auth or a server error
+func IsStorageFailure(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		IsUnauthorized(err) || errors.Is(err, ServerError)
+}
+
 func IsNotSupportError(err error) bool {
 	return errors.Is(pkgerr.Cause(err), NotSupport)
 }
@@ -1,9 +1,15 @@
 package errs
 
 import (
+	"context"
 	"errors"
-	pkgerr "github.com/pkg/errors"
+	"fmt"
+	"net/url"
+	"os"
+	"syscall"
 	"testing"
+
+	pkgerr "github.com/pkg/errors"
 )
 
 func TestErrs(t *testing.T) {
@@ -25,3 +31,22 @@ func TestErrs(t *testing.T) {
 		t.Errorf("failed, expect %s is %s", err2, StorageNotFound)
 	}
 }
+
+func TestIsStorageFailure(t *testing.T) {
+	tests := map[error]bool{
+		&url.Error{Op: "Get", URL: "http://x", Err: syscall.ECONNREFUSED}:     true,
+		pkgerr.WithMessage(context.DeadlineExceeded, "link"):                  true,
+		NewErr(Unauthorized, "code: 401"):                                     true,
+		fmt.Errorf("failed get link: %w", NewErr(ServerError, "status: 502")): true,
+		pkgerr.WithMessage(ObjectNotFound, "failed get file"):                 false,
+		PermissionDenied:                              false,
+		pkgerr.WithStack(os.ErrPermission):            false,
+		context.Canceled:                              false,
+		errors.New("file is not allowed to download"): false,
+	}
+	for err, expected := range tests {
+		if IsStorageFailure(err) != expected {
+			t.Errorf("IsStorageFailure(%q) should be %v", err, expected)
+		}
+	}
+}
@@ -5,3 +5,11 @@ import "errors"
 var (
 	PermissionDenied = errors.New("permission denied")
 )
+
+var (
+	QuotaExceeded = errors.New("upload quota exceeded")
+	FileTooLarge  = errors.New("file size exceeds the limit")
+	ExtNotAllowed = errors.New("file extension is not allowed")
+
+	ChecksumMismatch = errors.New("checksum mismatch")
+)
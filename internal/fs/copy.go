@@ -13,8 +13,10 @@ import (
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/internal/webhook"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"github.com/xhofe/tache"
 )
 
@@ -27,10 +29,20 @@ type CopyTask struct {
 	dstStorage   driver.Driver `json:"-"`
 	SrcStorageMp string        `json:"src_storage_mp"`
 	DstStorageMp string        `json:"dst_storage_mp"`
+	// Move removes the src once copied, a dir is copied in this task instead of a task per obj
+	Move bool `json:"move"`
+	// TrashItem is recorded once the src is moved to trash
+	TrashItem *model.TrashItem `json:"trash_item,omitempty"`
+	// isDir is set if the objs of the src dir are copied by their own tasks
+	isDir bool
 }
 
 func (t *CopyTask) GetName() string {
-	return fmt.Sprintf("copy [%s](%s) to [%s](%s)", t.SrcStorageMp, t.SrcObjPath, t.DstStorageMp, t.DstDirPath)
+	action := "copy"
+	if t.Move {
+		action = "move"
+	}
+	return fmt.Sprintf("%s [%s](%s) to [%s](%s)", action, t.SrcStorageMp, t.SrcObjPath, t.DstStorageMp, t.DstDirPath)
 }
 
 func (t *CopyTask) GetStatus() string {
@@ -51,14 +63,43 @@ func (t *CopyTask) Run() error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if t.Move {
+		return moveBetween2Storages(t, t.srcStorage, t.dstStorage, t.SrcObjPath, t.DstDirPath)
+	}
 	return copyBetween2Storages(t, t.srcStorage, t.dstStorage, t.SrcObjPath, t.DstDirPath)
 }
 
+func (t *CopyTask) OnSucceeded() {
+	if t.TrashItem != nil {
+		if err := saveTrashItem(t.TrashItem); err != nil {
+			log.Errorf("failed save trash item of %s: %+v", t.TrashItem.OriginalPath, err)
+		}
+	}
+	if !t.Move && !t.isDir {
+		data := webhook.FileData{
+			Path:    stdpath.Join(utils.GetActualMountPath(t.SrcStorageMp), t.SrcObjPath),
+			DstPath: stdpath.Join(utils.GetActualMountPath(t.DstStorageMp), t.DstDirPath, stdpath.Base(t.SrcObjPath)),
+		}
+		if t.GetCreator() != nil {
+			data.User = t.GetCreator().Username
+		}
+		webhook.Emit(webhook.FileCopied, data)
+	}
+	webhook.EmitTask("copy", t)
+}
+
+func (t *CopyTask) OnFailed() {
+	webhook.EmitTask("copy", t)
+}
+
 var CopyTaskManager *tache.Manager[*CopyTask]
 
 // Copy if in the same storage, call move method
 // if not, add copy task
 func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool) (task.TaskExtensionInfo, error) {
+	if err := checkInternalAccess(ctx, srcObjPath, dstDirPath); err != nil {
+		return nil, err
+	}
 	srcStorage, srcObjActualPath, err := op.GetStorageAndActualPath(srcObjPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get src storage")
@@ -93,7 +134,10 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 			if err != nil {
 				return nil, errors.WithMessagef(err, "failed get [%s] stream", srcObjPath)
 			}
-			return nil, op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
+			if err = op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false); err != nil {
+				return nil, err
+			}
+			return nil, op.VerifyHash(ctx, dstStorage, stdpath.Join(dstDirActualPath, srcObj.GetName()), srcObj.GetHash())
 		}
 	}
 	// not in the same storage
@@ -120,6 +164,7 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 		return errors.WithMessagef(err, "failed get src [%s] file", srcObjPath)
 	}
 	if srcObj.IsDir() {
+		t.isDir = true
 		t.Status = "src object is dir, listing objs"
 		objs, err := op.List(t.Ctx(), srcStorage, srcObjPath, model.ListArgs{})
 		if err != nil {
@@ -149,13 +194,31 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 	return copyFileBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstDirPath)
 }
 
+func moveBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) error {
+	t.Status = "copying"
+	if err := copyDirectly(t.Ctx(), srcStorage, srcObjPath, dstStorage, dstDirPath); err != nil {
+		return err
+	}
+	t.Status = "removing src"
+	return op.Remove(t.Ctx(), srcStorage, srcObjPath)
+}
+
 func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Driver, srcFilePath, dstDirPath string) error {
 	srcFile, err := op.Get(tsk.Ctx(), srcStorage, srcFilePath)
 	if err != nil {
 		return errors.WithMessagef(err, "failed get src [%s] file", srcFilePath)
 	}
 	tsk.SetTotalBytes(srcFile.GetSize())
-	link, _, err := op.Link(tsk.Ctx(), srcStorage, srcFilePath, model.LinkArgs{
+	if err = putFileBetween2Storages(tsk.Ctx(), srcStorage, dstStorage, srcFile, srcFilePath, dstDirPath, tsk.SetProgress); err != nil {
+		return err
+	}
+	tsk.Status = "verifying hash"
+	return op.VerifyHash(tsk.Ctx(), dstStorage, stdpath.Join(dstDirPath, srcFile.GetName()), srcFile.GetHash())
+}
+
+// putFileBetween2Storages streams the link of srcFile into dstDirPath of dstStorage
+func putFileBetween2Storages(ctx context.Context, srcStorage, dstStorage driver.Driver, srcFile model.Obj, srcFilePath, dstDirPath string, up driver.UpdateProgress) error {
+	link, _, err := op.Link(ctx, srcStorage, srcFilePath, model.LinkArgs{
 		Header: http.Header{},
 	})
 	if err != nil {
@@ -163,12 +226,12 @@ func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Drive
 	}
 	fs := stream.FileStream{
 		Obj: srcFile,
-		Ctx: tsk.Ctx(),
+		Ctx: ctx,
 	}
 	// any link provided is seekable
 	ss, err := stream.NewSeekableStream(fs, link)
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
 	}
-	return op.Put(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.SetProgress, true)
+	return op.Put(ctx, dstStorage, dstDirPath, ss, up, true)
 }
@@ -0,0 +1,189 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+const (
+	DefaultDuConcurrency = 4
+	MaxDuConcurrency     = 16
+)
+
+// DuTask computes the recursive size of a dir and all dirs under it
+type DuTask struct {
+	task.TaskExtension
+	Status      string `json:"-"`
+	Path        string `json:"path"`
+	Concurrency int    `json:"concurrency"` // number of subdirs walked at the same time
+	mu          sync.Mutex
+	sizes       map[string]*model.DirSize
+	scanned     int
+}
+
+func (t *DuTask) GetName() string {
+	return fmt.Sprintf("calculate size of [%s]", t.Path)
+}
+
+func (t *DuTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *DuTask) Run() error {
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	root, err := Get(t.Ctx(), t.Path, &GetArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s]", t.Path)
+	}
+	if !root.IsDir() {
+		return errors.Errorf("[%s] is not a dir", t.Path)
+	}
+	objs, err := List(t.Ctx(), t.Path, &ListArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", t.Path)
+	}
+	t.sizes = map[string]*model.DirSize{t.Path: {Path: t.Path}}
+	t.scanned = 0
+	// the subdirs of the root are walked concurrently
+	sem := make(chan struct{}, t.Concurrency)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		walkErr  error
+		finished int
+	)
+	for _, obj := range objs {
+		p := stdpath.Join(t.Path, obj.GetName())
+		if !obj.IsDir() {
+			if err = t.add(p, obj); err != nil {
+				errOnce.Do(func() { walkErr = err })
+				break
+			}
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p string, obj model.Obj) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := t.walk(p, obj); err != nil {
+				errOnce.Do(func() { walkErr = err })
+			}
+			t.mu.Lock()
+			finished++
+			t.SetProgress(float64(finished) * 100 / float64(len(objs)))
+			t.mu.Unlock()
+		}(p, obj)
+	}
+	wg.Wait()
+	if walkErr != nil {
+		return walkErr
+	}
+	t.Status = "saving"
+	sizes := make([]model.DirSize, 0, len(t.sizes))
+	for _, s := range t.sizes {
+		sizes = append(sizes, *s)
+	}
+	if err = op.ReplaceDirSizes(t.Path, sizes); err != nil {
+		return err
+	}
+	total := t.sizes[t.Path]
+	t.Status = fmt.Sprintf("%d files, %d dirs, %d bytes", total.Files, total.Dirs, total.Size)
+	t.SetProgress(100)
+	return nil
+}
+
+// walk counts the dir and everything under it, unlike WalkFS a failed list fails the walk,
+// so a partial total is never saved
+func (t *DuTask) walk(path string, dir model.Obj) error {
+	if err := t.add(path, dir); err != nil {
+		return err
+	}
+	meta, _ := op.GetNearestMeta(path)
+	objs, err := List(context.WithValue(t.Ctx(), "meta", meta), path, &ListArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", path)
+	}
+	for _, obj := range objs {
+		p := stdpath.Join(path, obj.GetName())
+		if obj.IsDir() {
+			err = t.walk(p, obj)
+		} else {
+			err = t.add(p, obj)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add counts the obj in all dirs above it up to the root
+func (t *DuTask) add(reqPath string, obj model.Obj) error {
+	if utils.IsCanceled(t.Ctx()) {
+		return t.Ctx().Err()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if obj.IsDir() {
+		if _, ok := t.sizes[reqPath]; !ok {
+			t.sizes[reqPath] = &model.DirSize{Path: reqPath}
+		}
+	}
+	for p := stdpath.Dir(reqPath); ; p = stdpath.Dir(p) {
+		s, ok := t.sizes[p]
+		if !ok {
+			// dirs are visited before what's in them, so this should not happen
+			s = &model.DirSize{Path: p}
+			t.sizes[p] = s
+		}
+		if obj.IsDir() {
+			s.Dirs++
+		} else {
+			s.Files++
+			s.Size += obj.GetSize()
+		}
+		if p == t.Path || p == "/" {
+			break
+		}
+	}
+	t.scanned++
+	t.Status = fmt.Sprintf("scanned %d objs", t.scanned)
+	return nil
+}
+
+var DuTaskManager *tache.Manager[*DuTask]
+
+// Du adds a task calculating the size of the dir and all dirs under it
+func Du(ctx context.Context, path string, concurrency int) (*DuTask, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultDuConcurrency
+	}
+	if concurrency > MaxDuConcurrency {
+		concurrency = MaxDuConcurrency
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t := &DuTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		Path:        utils.FixAndCleanPath(path),
+		Concurrency: concurrency,
+	}
+	DuTaskManager.Add(t)
+	return t, nil
+}
@@ -0,0 +1,81 @@
+package fs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestDu(t *testing.T) {
+	root := mountLocal(t, model.Storage{MountPath: "/du"})
+	writeFiles(t, root, map[string]string{
+		"a.txt":       "12345",
+		"x/b.txt":     "123",
+		"x/y/c.txt":   "1234567",
+		"x/y/z/d.txt": "1",
+		"w/e.txt":     "12",
+	}, time.Now())
+
+	runTask(t, &DuTask{Path: "/du", Concurrency: 2})
+	want := map[string][3]int64{
+		"/du":       {18, 5, 4},
+		"/du/x":     {11, 3, 2},
+		"/du/x/y":   {8, 2, 1},
+		"/du/x/y/z": {1, 1, 0},
+		"/du/w":     {2, 1, 0},
+	}
+	paths := make([]string, 0, len(want))
+	for p := range want {
+		paths = append(paths, p)
+	}
+	sizes, err := op.GetDirSizes(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, w := range want {
+		s := sizes[p]
+		if got := [3]int64{s.Size, s.Files, s.Dirs}; got != w {
+			t.Errorf("%s: got %v, want %v", p, got, w)
+		}
+	}
+
+	// a new calculation of a subdir replaces its sizes only
+	writeFiles(t, root, map[string]string{"x/y/f.txt": "12"}, time.Now())
+	runTask(t, &DuTask{Path: "/du/x/y", Concurrency: 1})
+	if s, _ := op.GetDirSize("/du/x/y"); s == nil || s.Size != 10 {
+		t.Errorf("/du/x/y: got %+v", s)
+	}
+	if s, _ := op.GetDirSize("/du/x/y/z"); s == nil || s.Size != 1 {
+		t.Errorf("/du/x/y/z: got %+v", s)
+	}
+	if s, _ := op.GetDirSize("/du"); s != nil {
+		t.Errorf("/du: stale size kept %+v", s)
+	}
+
+	// a write drops the sizes of the changed dirs and keeps the others
+	if err = Remove(context.Background(), "/du/x/y/f.txt"); err != nil {
+		t.Fatalf("remove: %+v", err)
+	}
+	for _, p := range []string{"/du/x/y", "/du/x"} {
+		if s, _ := op.GetDirSize(p); s != nil {
+			t.Errorf("%s: stale size kept %+v", p, s)
+		}
+	}
+	if s, _ := op.GetDirSize("/du/x/y/z"); s == nil || s.Size != 1 {
+		t.Errorf("/du/x/y/z: got %+v", s)
+	}
+	if s, _ := op.GetDirSize("/du/w"); s == nil || s.Size != 2 {
+		t.Errorf("/du/w: got %+v", s)
+	}
+
+	// a new dir counts in its parents too
+	if err = MakeDir(context.Background(), "/du/w/new"); err != nil {
+		t.Fatalf("make dir: %+v", err)
+	}
+	if s, _ := op.GetDirSize("/du/w"); s != nil {
+		t.Errorf("/du/w: stale size kept %+v", s)
+	}
+}
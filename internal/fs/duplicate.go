@@ -0,0 +1,360 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+// DuplicatePointerExt is the extension of the files replacing duplicates, they contain the link of the kept file
+const DuplicatePointerExt = ".strm"
+
+type DuplicateTask struct {
+	task.TaskExtension
+	Status  string   `json:"-"`
+	Paths   []string `json:"paths"`
+	MinSize int64    `json:"min_size"`
+}
+
+func (t *DuplicateTask) GetName() string {
+	return fmt.Sprintf("find duplicates in %s", strings.Join(t.Paths, ", "))
+}
+
+func (t *DuplicateTask) GetStatus() string {
+	return t.Status
+}
+
+type duplicateCandidate struct {
+	path string
+	obj  model.Obj
+}
+
+func (t *DuplicateTask) Run() error {
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	t.Status = "walking"
+	bySize, err := t.walk()
+	if err != nil {
+		return err
+	}
+	sizes := make([]int64, 0, len(bySize))
+	total := 0
+	for size, candidates := range bySize {
+		if len(candidates) > 1 {
+			sizes = append(sizes, size)
+			total += len(candidates)
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] > sizes[j] })
+	var groups []model.DuplicateGroup
+	done := 0
+	for _, size := range sizes {
+		candidates := bySize[size]
+		ht := preferredHashType(candidates)
+		byHash := make(map[string][]duplicateCandidate)
+		for _, c := range candidates {
+			if utils.IsCanceled(t.Ctx()) {
+				return t.Ctx().Err()
+			}
+			done++
+			t.Status = fmt.Sprintf("hashing %d/%d", done, total)
+			sum, err := hashObj(t.Ctx(), c, ht)
+			t.SetProgress(float64(done) * 100 / float64(total))
+			if err != nil {
+				log.Warnf("failed hash [%s]: %+v", c.path, err)
+				continue
+			}
+			byHash[sum] = append(byHash[sum], c)
+		}
+		sums := make([]string, 0, len(byHash))
+		for sum := range byHash {
+			sums = append(sums, sum)
+		}
+		sort.Strings(sums)
+		for _, sum := range sums {
+			same := byHash[sum]
+			if len(same) < 2 {
+				continue
+			}
+			group := model.DuplicateGroup{Size: size, Hash: ht.Name + ":" + sum}
+			for _, c := range same {
+				group.Files = append(group.Files, model.DuplicateFile{Path: c.path, Modified: c.obj.ModTime()})
+			}
+			groups = append(groups, group)
+		}
+	}
+	if err = op.ReplaceDuplicateGroups(groups); err != nil {
+		return err
+	}
+	t.Status = fmt.Sprintf("found %d duplicate groups", len(groups))
+	return nil
+}
+
+// walk collects the files to compare grouped by size
+func (t *DuplicateTask) walk() (map[int64][]duplicateCandidate, error) {
+	minSize := t.MinSize
+	if minSize < 1 {
+		// empty files are all the same
+		minSize = 1
+	}
+	seen := make(map[string]struct{})
+	bySize := make(map[int64][]duplicateCandidate)
+	for _, p := range t.Paths {
+		obj, err := Get(t.Ctx(), p, &GetArgs{})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed get [%s]", p)
+		}
+		err = WalkFS(t.Ctx(), -1, p, obj, func(reqPath string, info model.Obj) error {
+			if utils.IsCanceled(t.Ctx()) {
+				return t.Ctx().Err()
+			}
+			if info.IsDir() || info.GetSize() < minSize {
+				return nil
+			}
+			// the paths to scan may overlap
+			if _, ok := seen[reqPath]; ok {
+				return nil
+			}
+			seen[reqPath] = struct{}{}
+			bySize[info.GetSize()] = append(bySize[info.GetSize()], duplicateCandidate{path: reqPath, obj: info})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bySize, nil
+}
+
+// preferredHashType returns the hash type most files already have a hash of, md5 if none has
+func preferredHashType(candidates []duplicateCandidate) *utils.HashType {
+	counts := make(map[*utils.HashType]int)
+	for _, c := range candidates {
+		for ht, sum := range c.obj.GetHash().Export() {
+			if sum != "" {
+				counts[ht]++
+			}
+		}
+	}
+	best := utils.MD5
+	for _, ht := range utils.Supported {
+		if counts[ht] > counts[best] {
+			best = ht
+		}
+	}
+	return best
+}
+
+// hashObj returns the hash provided by the driver, the file is streamed to hash it if there is none
+func hashObj(ctx context.Context, c duplicateCandidate, ht *utils.HashType) (string, error) {
+	if sum := c.obj.GetHash().GetHash(ht); sum != "" {
+		return strings.ToLower(sum), nil
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(c.path)
+	if err != nil {
+		return "", err
+	}
+	link, obj, err := op.Link(ctx, storage, actualPath, model.LinkArgs{})
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get link")
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: ctx}, link)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	return utils.HashReader(ht, ss)
+}
+
+var DuplicateTaskManager *tache.Manager[*DuplicateTask]
+
+// FindDuplicates adds a task scanning the paths for duplicates, the groups found replace the stored ones
+func FindDuplicates(ctx context.Context, paths []string, minSize int64) (*DuplicateTask, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no path to scan")
+	}
+	for i := range paths {
+		paths[i] = utils.FixAndCleanPath(paths[i])
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t := &DuplicateTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		Paths:   paths,
+		MinSize: minSize,
+	}
+	DuplicateTaskManager.Add(t)
+	return t, nil
+}
+
+// checkDuplicate makes sure the file still has the size and hash found by the scan,
+// the scan may be stale and nothing must be removed based on it
+func checkDuplicate(ctx context.Context, path string, group *model.DuplicateGroup) error {
+	obj, err := Get(ctx, path, &GetArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s]", path)
+	}
+	if obj.IsDir() || obj.GetSize() != group.Size {
+		return errors.Errorf("[%s] has changed since the scan", path)
+	}
+	name, expected, _ := strings.Cut(group.Hash, ":")
+	ht := utils.GetHashByName(name)
+	if ht == nil {
+		return errors.Errorf("unknown hash type of group %d: %s", group.ID, name)
+	}
+	sum, err := hashObj(ctx, duplicateCandidate{path: path, obj: obj}, ht)
+	if err != nil {
+		return errors.WithMessagef(err, "failed hash [%s]", path)
+	}
+	if sum != expected {
+		return errors.Errorf("[%s] has changed since the scan", path)
+	}
+	return nil
+}
+
+// keptDuplicates returns a file kept in the group of each file, which is checked to still have the
+// content of the group, each group must keep one
+func keptDuplicates(ctx context.Context, files []model.DuplicateFile) (map[uint]string, map[uint]*model.DuplicateGroup, error) {
+	handled := make(map[uint]struct{}, len(files))
+	for _, f := range files {
+		handled[f.ID] = struct{}{}
+	}
+	kept := make(map[uint]string)
+	groups := make(map[uint]*model.DuplicateGroup)
+	for _, f := range files {
+		if _, ok := groups[f.GroupID]; ok {
+			continue
+		}
+		group, err := op.GetDuplicateGroupById(f.GroupID)
+		if err != nil {
+			return nil, nil, err
+		}
+		groups[f.GroupID] = group
+		groupFiles, err := op.GetDuplicateFilesByGroupId(f.GroupID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, gf := range groupFiles {
+			if _, ok := handled[gf.ID]; ok || gf.Status != "" {
+				continue
+			}
+			if err = checkDuplicate(ctx, gf.Path, group); err != nil {
+				log.Warnf("can't keep duplicate: %+v", err)
+				continue
+			}
+			kept[f.GroupID] = gf.Path
+			break
+		}
+		if _, ok := kept[f.GroupID]; !ok {
+			return nil, nil, errors.Errorf("at least one unchanged file of duplicate group %d must be kept", f.GroupID)
+		}
+	}
+	return kept, groups, nil
+}
+
+// RemoveDuplicates removes the duplicate files, returns the errors by file id
+func RemoveDuplicates(ctx context.Context, ids []uint) (map[uint]string, error) {
+	return handleDuplicates(ctx, ids, func(f *model.DuplicateFile, kept string) error {
+		if err := Remove(ctx, f.Path); err != nil {
+			return err
+		}
+		f.Status = model.DuplicateFileRemoved
+		return nil
+	})
+}
+
+// ReplaceDuplicates replaces the duplicate files with pointers containing the path of a kept file of the group.
+// The path is stored instead of a link, so reading it still goes through the access checks of the kept file
+func ReplaceDuplicates(ctx context.Context, ids []uint) (map[uint]string, error) {
+	return handleDuplicates(ctx, ids, func(f *model.DuplicateFile, kept string) error {
+		storage, err := GetStorage(f.Path, &GetStoragesArgs{})
+		if err != nil {
+			return err
+		}
+		if storage.Config().NoUpload {
+			return errors.New("storage is not writable")
+		}
+		dir, name := stdpath.Split(f.Path)
+		s := &stream.FileStream{
+			Obj: &model.Object{
+				Name:     name + DuplicatePointerExt,
+				Size:     int64(len(kept)),
+				Modified: time.Now(),
+			},
+			Reader:   strings.NewReader(kept),
+			Mimetype: "text/plain",
+		}
+		// write the pointer first so nothing is lost if it fails
+		if err = PutDirectly(ctx, dir, s); err != nil {
+			return errors.WithMessage(err, "failed put pointer")
+		}
+		if err = Remove(ctx, f.Path); err != nil {
+			return err
+		}
+		f.Status = model.DuplicateFileReplaced
+		return nil
+	})
+}
+
+func handleDuplicates(ctx context.Context, ids []uint, handle func(f *model.DuplicateFile, kept string) error) (map[uint]string, error) {
+	files, err := op.GetDuplicateFilesByIds(ids)
+	if err != nil {
+		return nil, err
+	}
+	kept, groups, err := keptDuplicates(ctx, files)
+	if err != nil {
+		return nil, err
+	}
+	retErrs := make(map[uint]string)
+	for i := range files {
+		f := &files[i]
+		if f.Status != "" {
+			retErrs[f.ID] = "file has been " + f.Status
+			continue
+		}
+		group := groups[f.GroupID]
+		// the kept file may have been changed meanwhile, the file removed must still be a duplicate
+		if err := checkKept(ctx, kept[f.GroupID], group); err != nil {
+			retErrs[f.ID] = err.Error()
+			continue
+		}
+		if err := checkDuplicate(ctx, f.Path, group); err != nil {
+			retErrs[f.ID] = err.Error()
+			continue
+		}
+		if err := handle(f, kept[f.GroupID]); err != nil {
+			retErrs[f.ID] = err.Error()
+			continue
+		}
+		if err := op.UpdateDuplicateFile(f); err != nil {
+			retErrs[f.ID] = err.Error()
+		}
+	}
+	return retErrs, nil
+}
+
+// checkKept stats the kept file again before each remove, its hash is checked by keptDuplicates
+func checkKept(ctx context.Context, path string, group *model.DuplicateGroup) error {
+	obj, err := Get(ctx, path, &GetArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get kept file [%s]", path)
+	}
+	if obj.IsDir() || obj.GetSize() != group.Size {
+		return errors.Errorf("kept file [%s] has changed since the scan", path)
+	}
+	return nil
+}
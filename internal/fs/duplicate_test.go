@@ -0,0 +1,55 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestDuplicates(t *testing.T) {
+	rootA := mountLocal(t, model.Storage{MountPath: "/dup_a"})
+	rootB := mountLocal(t, model.Storage{MountPath: "/dup_b"})
+	writeFiles(t, rootA, map[string]string{"movie.mkv": "movie", "x/other.mkv": "other", "empty": ""}, time.Now())
+	writeFiles(t, rootB, map[string]string{"copy/movie.mkv": "movie", "diff.mkv": "mov1e", "empty": ""}, time.Now())
+
+	runTask(t, &DuplicateTask{Paths: []string{"/dup_a", "/dup_b", "/dup_a/x"}})
+	groups, total, err := op.GetDuplicateGroups(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(groups[0].Files) != 2 {
+		t.Fatalf("expected one group of two files, got %+v", groups)
+	}
+	files := groups[0].Files
+	if files[0].Path != "/dup_a/movie.mkv" || files[1].Path != "/dup_b/copy/movie.mkv" {
+		t.Fatalf("unexpected files %+v", files)
+	}
+
+	// the file to remove changed since the scan
+	writeFiles(t, rootB, map[string]string{"copy/movie.mkv": "mov1e"}, time.Now())
+	retErrs, err := ReplaceDuplicates(context.Background(), []uint{files[1].ID})
+	if err != nil || len(retErrs) != 1 {
+		t.Fatalf("changed file should not be replaced: %v %+v", retErrs, err)
+	}
+	writeFiles(t, rootB, map[string]string{"copy/movie.mkv": "movie"}, time.Now())
+	retErrs, err = ReplaceDuplicates(context.Background(), []uint{files[1].ID})
+	if err != nil || len(retErrs) != 0 {
+		t.Fatalf("replace: %v %+v", retErrs, err)
+	}
+	if _, err = os.Stat(filepath.Join(rootB, "copy/movie.mkv")); !os.IsNotExist(err) {
+		t.Fatalf("duplicate should be removed: %v", err)
+	}
+	pointer, _ := os.ReadFile(filepath.Join(rootB, "copy/movie.mkv"+DuplicatePointerExt))
+	if string(pointer) != "/dup_a/movie.mkv" {
+		t.Fatalf("unexpected pointer %q", pointer)
+	}
+	// the last file of the group must be kept
+	if _, err = RemoveDuplicates(context.Background(), []uint{files[0].ID}); err == nil {
+		t.Fatal("expected an error removing the last file of a group")
+	}
+}
@@ -2,10 +2,13 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
+
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/internal/webhook"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -45,7 +48,7 @@ func Get(ctx context.Context, path string, args *GetArgs) (model.Obj, error) {
 }
 
 func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
-	res, file, err := link(ctx, path, args)
+	res, file, _, err := link(ctx, path, args)
 	if err != nil {
 		log.Errorf("failed link %s: %+v", path, err)
 		return nil, nil, err
@@ -53,10 +56,23 @@ func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, m
 	return res, file, nil
 }
 
+// LinkForProxy is Link for the files proxied by alist, call done once the file is sent,
+// so the proxied downloads count as connections of the storage for balancing
+func LinkForProxy(ctx context.Context, path string, args model.LinkArgs) (res *model.Link, file model.Obj, done func(), err error) {
+	res, file, storage, err := link(ctx, path, args)
+	if err != nil {
+		log.Errorf("failed link %s: %+v", path, err)
+		return nil, nil, nil, err
+	}
+	return res, file, op.TrackActive(storage), nil
+}
+
 func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	err := makeDir(ctx, path, lazyCache...)
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
+	} else {
+		emitFileEvent(ctx, webhook.DirCreated, path, "")
 	}
 	return err
 }
@@ -65,6 +81,8 @@ func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 	err := move(ctx, srcPath, dstDirPath, lazyCache...)
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
+	} else {
+		emitFileEvent(ctx, webhook.FileMoved, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
 	}
 	return err
 }
@@ -73,6 +91,9 @@ func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool)
 	res, err := _copy(ctx, srcObjPath, dstDirPath, lazyCache...)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
+	} else if res == nil {
+		// copied without a task, a copy task emits it once succeeded
+		emitFileEvent(ctx, webhook.FileCopied, srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath)))
 	}
 	return res, err
 }
@@ -81,6 +102,8 @@ func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	err := rename(ctx, srcPath, dstName, lazyCache...)
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
+	} else {
+		emitFileEvent(ctx, webhook.FileRenamed, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
 	}
 	return err
 }
@@ -89,14 +112,23 @@ func Remove(ctx context.Context, path string) error {
 	err := remove(ctx, path)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
+	} else {
+		emitFileEvent(ctx, webhook.FileRemoved, path, "")
 	}
 	return err
 }
 
 func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, lazyCache ...bool) error {
-	err := putDirectly(ctx, dstDirPath, file, lazyCache...)
+	return PutDirectlyWithProgress(ctx, dstDirPath, file, nil, lazyCache...)
+}
+
+// PutDirectlyWithProgress is PutDirectly reporting the progress of the upload to up
+func PutDirectlyWithProgress(ctx context.Context, dstDirPath string, file model.FileStreamer, up driver.UpdateProgress, lazyCache ...bool) error {
+	err := putDirectly(ctx, dstDirPath, file, up, lazyCache...)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
+	} else {
+		emitFileEvent(ctx, webhook.FileUploaded, stdpath.Join(dstDirPath, file.GetName()), "")
 	}
 	return err
 }
@@ -109,6 +141,12 @@ func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 	return t, err
 }
 
+// CopyDirectly copies the object between two storages and returns after finish,
+// it's used by the drivers built on other storages, so no hook of this package is run
+func CopyDirectly(ctx context.Context, srcStorage driver.Driver, srcObjPath string, dstStorage driver.Driver, dstDirPath string) error {
+	return copyDirectly(ctx, srcStorage, srcObjPath, dstStorage, dstDirPath)
+}
+
 type GetStoragesArgs struct {
 }
 
@@ -127,3 +165,24 @@ func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	}
 	return res, err
 }
+
+// IsWriteOther reports whether the Other method changes data in the storage of the path
+func IsWriteOther(path, method string) bool {
+	storage, err := GetStorage(path, &GetStoragesArgs{})
+	if err != nil {
+		return false
+	}
+	w, ok := storage.(driver.WriteOther)
+	return ok && w.IsWriteMethod(method)
+}
+
+func emitFileEvent(ctx context.Context, event, path, dstPath string) {
+	data := webhook.FileData{
+		Path:    path,
+		DstPath: dstPath,
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		data.User = user.Username
+	}
+	webhook.Emit(event, data)
+}
@@ -13,6 +13,9 @@ import (
 
 func get(ctx context.Context, path string) (model.Obj, error) {
 	path = utils.FixAndCleanPath(path)
+	if err := checkInternalAccess(ctx, path); err != nil {
+		return nil, err
+	}
 	// maybe a virtual file
 	if path != "/" {
 		virtualFiles := op.GetStorageVirtualFilesByPath(stdpath.Dir(path))
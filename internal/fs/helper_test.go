@@ -0,0 +1,55 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/testutil"
+)
+
+func init() {
+	testutil.InitDB()
+}
+
+// mountLocal mounts a Local storage on a new temp dir at storage.MountPath, the dir is returned
+func mountLocal(t *testing.T, storage model.Storage) string {
+	root := t.TempDir()
+	storage.Driver = "Local"
+	storage.Addition = `{"root_folder_path":"` + root + `"}`
+	if _, err := op.CreateStorage(context.Background(), storage); err != nil {
+		t.Fatalf("create storage: %+v", err)
+	}
+	return root
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string, mtime time.Time) {
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// runTask runs the task without a manager
+func runTask(t *testing.T, task interface {
+	SetCtx(ctx context.Context)
+	Run() error
+}) {
+	task.SetCtx(context.Background())
+	if err := task.Run(); err != nil {
+		t.Fatalf("run: %+v", err)
+	}
+}
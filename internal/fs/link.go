@@ -4,6 +4,8 @@ import (
 	"context"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
@@ -11,19 +13,31 @@ import (
 	"github.com/pkg/errors"
 )
 
-func link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
+// link returns the storage serving the link too, which may be any one of balanced storages
+func link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, driver.Driver, error) {
+	if err := checkInternalAccess(ctx, path); err != nil {
+		return nil, nil, nil, err
+	}
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil {
-		return nil, nil, errors.WithMessage(err, "failed get storage")
+		return nil, nil, nil, errors.WithMessage(err, "failed get storage")
 	}
 	l, obj, err := op.Link(ctx, storage, actualPath, args)
+	// the failed storage is ejected, so retry on the next healthy one of balanced storages
+	for i := 1; err != nil && !errs.IsObjectNotFound(err) && i < op.GetBalancedStoragesNum(path); i++ {
+		storage, actualPath, err = op.GetStorageAndActualPath(path)
+		if err != nil {
+			return nil, nil, nil, errors.WithMessage(err, "failed get storage")
+		}
+		l, obj, err = op.Link(ctx, storage, actualPath, args)
+	}
 	if err != nil {
-		return nil, nil, errors.WithMessage(err, "failed link")
+		return nil, nil, nil, errors.WithMessage(err, "failed link")
 	}
 	if l.URL != "" && !strings.HasPrefix(l.URL, "http://") && !strings.HasPrefix(l.URL, "https://") {
 		if c, ok := ctx.(*gin.Context); ok {
 			l.URL = common.GetApiUrl(c.Request) + l.URL
 		}
 	}
-	return l, obj, nil
+	return l, obj, storage, nil
 }
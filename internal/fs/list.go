@@ -2,7 +2,9 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -14,6 +16,9 @@ import (
 func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error) {
 	meta, _ := ctx.Value("meta").(*model.Meta)
 	user, _ := ctx.Value("user").(*model.User)
+	if err := checkInternalAccess(ctx, path); err != nil {
+		return nil, err
+	}
 	virtualFiles := op.GetStorageVirtualFilesByPath(path)
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil && len(virtualFiles) == 0 {
@@ -36,6 +41,9 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 		}
 	}
 
+	if storage != nil {
+		_objs = hideInternalDirs(storage, path, _objs)
+	}
 	om := model.NewObjMerge()
 	if whetherHide(user, meta, path) {
 		om.InitHideReg(meta.Hide)
@@ -44,6 +52,33 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 	return objs, nil
 }
 
+// hideInternalDirs hides the trash and versions dirs of the storage
+func hideInternalDirs(storage driver.Driver, path string, objs []model.Obj) []model.Obj {
+	var dirs []string
+	if isTrashEnabled(storage) {
+		dirs = append(dirs, trashRoot(storage))
+	}
+	if isVersioningEnabled(storage) {
+		dirs = append(dirs, stdpath.Join(utils.GetActualMountPath(storage.GetStorage().MountPath), VersionsDirName))
+	}
+	names := make(map[string]struct{})
+	for _, dir := range dirs {
+		if utils.PathEqual(stdpath.Dir(dir), path) {
+			names[stdpath.Base(dir)] = struct{}{}
+		}
+	}
+	if len(names) == 0 {
+		return objs
+	}
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		if _, ok := names[obj.GetName()]; !ok {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
 func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	// if is admin, don't hide
 	if user == nil || user.CanSeeHides() {
@@ -2,10 +2,12 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 )
 
@@ -18,6 +20,9 @@ func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
 }
 
 func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
+	if err := checkInternalAccess(ctx, srcPath, dstDirPath); err != nil {
+		return err
+	}
 	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get src storage")
@@ -29,23 +34,47 @@ func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 	if srcStorage.GetStorage() != dstStorage.GetStorage() {
 		return errors.WithStack(errs.MoveBetweenTwoStorages)
 	}
-	return op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...)
+	if err = op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...); err != nil {
+		return err
+	}
+	moveVersions(srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	moveUploads(srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	return nil
 }
 
 func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
+	if err := checkInternalAccess(ctx, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName)); err != nil {
+		return err
+	}
 	storage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...)
+	if err = op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...); err != nil {
+		return err
+	}
+	moveVersions(srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
+	moveUploads(srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
+	return nil
 }
 
 func remove(ctx context.Context, path string) error {
+	if err := checkInternalAccess(ctx, path); err != nil {
+		return err
+	}
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.Remove(ctx, storage, actualPath)
+	if isTrashEnabled(storage) && !utils.IsSubPath(trashRoot(storage), path) {
+		return moveToTrash(ctx, storage, path, actualPath)
+	}
+	if err = op.Remove(ctx, storage, actualPath); err != nil {
+		return err
+	}
+	releaseUploads(path)
+	deleteVersions(ctx, path)
+	return nil
 }
 
 func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
@@ -0,0 +1,69 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/xhofe/tache"
+)
+
+// OtherTask runs a long Other method of a storage in background, the result is kept in the task
+type OtherTask struct {
+	task.TaskExtension
+	Status string `json:"-"`
+	Path   string `json:"path"`
+	Method string `json:"method"`
+	run    func(t *OtherTask) (interface{}, error)
+	result interface{}
+}
+
+func (t *OtherTask) GetName() string {
+	return fmt.Sprintf("%s [%s]", t.Method, t.Path)
+}
+
+func (t *OtherTask) GetStatus() string {
+	return t.Status
+}
+
+// SetStatus is used by the driver to report what it's doing
+func (t *OtherTask) SetStatus(status string) {
+	t.Status = status
+}
+
+func (t *OtherTask) GetResult() interface{} {
+	return t.result
+}
+
+func (t *OtherTask) Run() error {
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	var err error
+	t.result, err = t.run(t)
+	if err == nil {
+		t.Status = "done"
+		t.SetProgress(100)
+	}
+	return err
+}
+
+var OtherTaskManager *tache.Manager[*OtherTask]
+
+// OtherAsTask adds a task running the method of path with run, the ctx of run is canceled with the task
+func OtherAsTask(ctx context.Context, path, method string, run func(t *OtherTask) (interface{}, error)) *OtherTask {
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t := &OtherTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		Path:   utils.FixAndCleanPath(path),
+		Method: method,
+		run:    run,
+	}
+	OtherTaskManager.Add(t)
+	return t
+}
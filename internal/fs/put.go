@@ -8,8 +8,11 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/internal/webhook"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	"github.com/xhofe/tache"
+	stdpath "path"
 	"time"
 )
 
@@ -18,6 +21,7 @@ type UploadTask struct {
 	storage          driver.Driver
 	dstDirActualPath string
 	file             model.FileStreamer
+	size             int64 // the stream can't tell its size once closed
 }
 
 func (t *UploadTask) GetName() string {
@@ -32,13 +36,31 @@ func (t *UploadTask) Run() error {
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
-	return op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+	return putWithVersion(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+}
+
+func (t *UploadTask) OnSucceeded() {
+	var userId uint
+	if t.Creator != nil {
+		userId = t.Creator.ID
+	}
+	recordUpload(userId, stdpath.Join(utils.GetActualMountPath(t.storage.GetStorage().MountPath), t.dstDirActualPath, t.file.GetName()), t.size)
+	emitFileEvent(t.Ctx(), webhook.FileUploaded, stdpath.Join(t.storage.GetStorage().MountPath, t.dstDirActualPath, t.file.GetName()), "")
+	webhook.EmitTask("upload", t)
+}
+
+func (t *UploadTask) OnFailed() {
+	webhook.EmitTask("upload", t)
 }
 
 var UploadTaskManager *tache.Manager[*UploadTask]
 
 // putAsTask add as a put task and return immediately
 func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskExtensionInfo, error) {
+	size, err := checkPutLimit(ctx, dstDirPath, file)
+	if err != nil {
+		return nil, err
+	}
 	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get storage")
@@ -62,14 +84,19 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 		storage:          storage,
 		dstDirActualPath: dstDirActualPath,
 		file:             file,
+		size:             size,
 	}
-	t.SetTotalBytes(file.GetSize())
+	t.SetTotalBytes(size)
 	UploadTaskManager.Add(t)
 	return t, nil
 }
 
 // putDirect put the file and return after finish
-func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, lazyCache ...bool) error {
+func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, up driver.UpdateProgress, lazyCache ...bool) error {
+	size, err := checkPutLimit(ctx, dstDirPath, file)
+	if err != nil {
+		return err
+	}
 	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
@@ -77,5 +104,9 @@ func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
-	return op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	err = putWithVersion(ctx, storage, dstDirActualPath, file, up, lazyCache...)
+	if err == nil {
+		recordUpload(userIdOf(ctx), stdpath.Join(dstDirPath, file.GetName()), size)
+	}
+	return err
 }
@@ -0,0 +1,96 @@
+package fs
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// CheckUploadLimit checks the upload limits of the user in ctx and the nearest meta of dstPath,
+// size < 0 means unknown and only the file name is checked
+func CheckUploadLimit(ctx context.Context, dstPath string, size int64) error {
+	name := stdpath.Base(dstPath)
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		if err := user.CheckName(name); err != nil {
+			return err
+		}
+		var used int64
+		if user.Quota > 0 && size >= 0 {
+			var err error
+			if used, err = op.GetUserUploadedBytes(user.ID); err != nil {
+				return err
+			}
+		}
+		if err := user.CheckSize(size, used); err != nil {
+			return err
+		}
+	}
+	meta, err := op.GetNearestMeta(stdpath.Dir(dstPath))
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return err
+		}
+		return nil
+	}
+	if err = meta.CheckName(name); err != nil {
+		return err
+	}
+	var used int64
+	if meta.Quota > 0 && size >= 0 {
+		if used, err = op.GetPathUploadedBytes(meta.Path); err != nil {
+			return err
+		}
+	}
+	return meta.CheckSize(size, used)
+}
+
+// checkPutLimit checks the upload limits with the real size of the file and returns it,
+// a file of unknown size, such as a chunked request, is cached first to know it
+func checkPutLimit(ctx context.Context, dstDirPath string, file model.FileStreamer) (int64, error) {
+	if file.GetSize() < 0 {
+		if _, err := file.CacheFullInTempFile(); err != nil {
+			return 0, errors.WithMessage(err, "failed cache the file of unknown size")
+		}
+	}
+	size := file.GetSize()
+	return size, CheckUploadLimit(ctx, stdpath.Join(dstDirPath, file.GetName()), size)
+}
+
+// recordUpload saves the audit of the finished upload, replacing the one of the overwritten file
+func recordUpload(userId uint, path string, size int64) {
+	err := op.ReplaceUploadRecord(&model.UploadRecord{
+		UserId: userId,
+		Path:   path,
+		Size:   size,
+	})
+	if err != nil {
+		log.Errorf("failed record upload of %s: %+v", path, err)
+	}
+}
+
+// releaseUploads stops counting the removed file, or the files in the removed dir, in the quotas
+func releaseUploads(path string) {
+	if err := op.DeleteUploadRecords(utils.FixAndCleanPath(path)); err != nil {
+		log.Errorf("failed delete upload records of %s: %+v", path, err)
+	}
+}
+
+// moveUploads keeps the upload records with the file after it's moved or renamed
+func moveUploads(oldPath, newPath string) {
+	if err := op.MoveUploadRecords(utils.FixAndCleanPath(oldPath), utils.FixAndCleanPath(newPath)); err != nil {
+		log.Errorf("failed move upload records of %s: %+v", oldPath, err)
+	}
+}
+
+func userIdOf(ctx context.Context) uint {
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		return user.ID
+	}
+	return 0
+}
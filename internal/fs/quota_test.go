@@ -0,0 +1,53 @@
+package fs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/pkg/errors"
+)
+
+func TestUserQuota(t *testing.T) {
+	mountLocal(t, model.Storage{MountPath: "/quota"})
+	conf.Conf.TempDir = t.TempDir()
+	user := &model.User{ID: 1000, Role: model.GENERAL, UploadLimit: model.UploadLimit{Quota: 10}}
+	ctx := context.WithValue(context.Background(), "user", user)
+	put := func(name, content string, size int64) error {
+		return PutDirectly(ctx, "/quota", &stream.FileStream{
+			Obj:    &model.Object{Name: name, Size: size, Modified: time.Now()},
+			Reader: strings.NewReader(content),
+		})
+	}
+	used := func() int64 {
+		n, err := op.GetUserUploadedBytes(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	for i := 0; i < 2; i++ {
+		if err := put("a.txt", "1234", 4); err != nil {
+			t.Fatalf("put: %+v", err)
+		}
+	}
+	if n := used(); n != 4 {
+		t.Errorf("an overwritten file should be counted once, used %d", n)
+	}
+	// the size of a chunked upload is only known once it's read
+	if err := put("b.txt", "123", -1); err != nil {
+		t.Fatalf("put: %+v", err)
+	}
+	if n := used(); n != 7 {
+		t.Errorf("the real size should be counted, used %d", n)
+	}
+	if err := put("c.txt", "12345", -1); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("expected quota exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,380 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/internal/webhook"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// syncMtimeTolerance absorbs the modified time precision lost by some storages
+const syncMtimeTolerance = 2 * time.Second
+
+const (
+	SyncOpCopy     = "copy"      // copy from the src to the dst
+	SyncOpCopyBack = "copy_back" // copy from the dst to the src, two-way only
+	SyncOpDelete   = "delete"    // delete from the dst, mirror only
+	SyncOpConflict = "conflict"  // a file on one side is a dir on the other, skipped
+	SyncOpSkip     = "skip"      // the files can't be compared, e.g. no common hash type, skipped
+)
+
+type SyncArgs struct {
+	SrcPath string   `json:"src_path"`
+	DstPath string   `json:"dst_path"`
+	Mode    string   `json:"mode"`
+	Compare string   `json:"compare"`
+	Include []string `json:"include"` // glob patterns matched against files, empty means all
+	Exclude []string `json:"exclude"` // glob patterns matched against files and dirs
+}
+
+// SyncAction is an operation planned by a sync, Path is relative to the src and dst
+type SyncAction struct {
+	Op     string `json:"op"`
+	Path   string `json:"path"`
+	IsDir  bool   `json:"is_dir"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason"`
+	Error  string `json:"error,omitempty"`
+}
+
+type SyncTask struct {
+	task.TaskExtension
+	SyncArgs
+	Status string       `json:"-"`
+	JobID  uint         `json:"job_id,omitempty"`
+	Report []SyncAction `json:"report"`
+	mu     sync.Mutex
+}
+
+func (t *SyncTask) GetName() string {
+	return fmt.Sprintf("sync [%s] to [%s] (%s)", t.SrcPath, t.DstPath, t.Mode)
+}
+
+func (t *SyncTask) GetStatus() string {
+	return t.Status
+}
+
+// GetReport returns a copy of the actions of the last run
+func (t *SyncTask) GetReport() []SyncAction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]SyncAction(nil), t.Report...)
+}
+
+func (t *SyncTask) Run() error {
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	t.Status = "comparing"
+	s, err := newSyncer(t.Ctx(), &t.SyncArgs)
+	if err != nil {
+		return err
+	}
+	actions, err := s.plan()
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.Report = actions
+	t.mu.Unlock()
+	var size int64
+	for _, a := range actions {
+		size += a.Size
+	}
+	t.SetTotalBytes(size)
+	failed := 0
+	for i, a := range actions {
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		t.Status = fmt.Sprintf("%s %s", a.Op, a.Path)
+		if err := s.apply(a); err != nil {
+			failed++
+			t.mu.Lock()
+			t.Report[i].Error = err.Error()
+			t.mu.Unlock()
+		}
+		t.SetProgress(float64(i+1) * 100 / float64(len(actions)))
+	}
+	t.Status = fmt.Sprintf("done, %d actions", len(actions))
+	if failed > 0 {
+		return errors.Errorf("%d of %d actions failed", failed, len(actions))
+	}
+	return nil
+}
+
+func (t *SyncTask) OnSucceeded() {
+	webhook.EmitTask("sync", t)
+}
+
+func (t *SyncTask) OnFailed() {
+	webhook.EmitTask("sync", t)
+}
+
+var SyncTaskManager *tache.Manager[*SyncTask]
+
+// Sync adds a task syncing args.SrcPath to args.DstPath
+func Sync(ctx context.Context, args SyncArgs, jobId uint) (*SyncTask, error) {
+	if err := validateSyncArgs(&args); err != nil {
+		return nil, err
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t := &SyncTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		SyncArgs: args,
+		JobID:    jobId,
+	}
+	SyncTaskManager.Add(t)
+	return t, nil
+}
+
+// PlanSync compares the src and dst and returns the actions a sync would take without applying them
+func PlanSync(ctx context.Context, args SyncArgs) ([]SyncAction, error) {
+	if err := validateSyncArgs(&args); err != nil {
+		return nil, err
+	}
+	s, err := newSyncer(ctx, &args)
+	if err != nil {
+		return nil, err
+	}
+	return s.plan()
+}
+
+func validateSyncArgs(args *SyncArgs) error {
+	args.SrcPath = utils.FixAndCleanPath(args.SrcPath)
+	args.DstPath = utils.FixAndCleanPath(args.DstPath)
+	return op.ValidateSyncArgs(args.SrcPath, args.DstPath, &args.Mode, &args.Compare, append(args.Include, args.Exclude...)...)
+}
+
+type syncer struct {
+	ctx      context.Context
+	args     *SyncArgs
+	src, dst driver.Driver
+	srcRoot  string
+	dstRoot  string
+	actions  []SyncAction
+}
+
+func newSyncer(ctx context.Context, args *SyncArgs) (*syncer, error) {
+	src, srcRoot, err := op.GetStorageAndActualPath(args.SrcPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get src storage")
+	}
+	dst, dstRoot, err := op.GetStorageAndActualPath(args.DstPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	return &syncer{ctx: ctx, args: args, src: src, dst: dst, srcRoot: srcRoot, dstRoot: dstRoot}, nil
+}
+
+func (s *syncer) plan() ([]SyncAction, error) {
+	srcObj, err := op.Get(s.ctx, s.src, s.srcRoot)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get src [%s]", s.args.SrcPath)
+	}
+	if !srcObj.IsDir() {
+		return nil, errors.Errorf("src [%s] is not a dir", s.args.SrcPath)
+	}
+	dstExists := true
+	if dstObj, err := op.Get(s.ctx, s.dst, s.dstRoot); err != nil {
+		if !errs.IsObjectNotFound(err) {
+			return nil, errors.WithMessagef(err, "failed get dst [%s]", s.args.DstPath)
+		}
+		dstExists = false
+	} else if !dstObj.IsDir() {
+		return nil, errors.Errorf("dst [%s] is not a dir", s.args.DstPath)
+	}
+	s.actions = nil
+	if err = s.compareDir("", true, dstExists); err != nil {
+		return nil, err
+	}
+	return s.actions, nil
+}
+
+func (s *syncer) list(storage driver.Driver, dir string, exists bool) (map[string]model.Obj, error) {
+	objs := make(map[string]model.Obj)
+	if !exists {
+		return objs, nil
+	}
+	list, err := op.List(s.ctx, storage, dir, model.ListArgs{Refresh: true})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed list [%s]", dir)
+	}
+	for _, obj := range list {
+		objs[obj.GetName()] = obj
+	}
+	return objs, nil
+}
+
+func (s *syncer) add(typ, rel string, obj model.Obj, reason string) {
+	a := SyncAction{Op: typ, Path: rel, IsDir: obj.IsDir(), Reason: reason}
+	if !obj.IsDir() {
+		a.Size = obj.GetSize()
+	}
+	s.actions = append(s.actions, a)
+}
+
+func (s *syncer) compareDir(rel string, srcExists, dstExists bool) error {
+	if utils.IsCanceled(s.ctx) {
+		return s.ctx.Err()
+	}
+	srcObjs, err := s.list(s.src, stdpath.Join(s.srcRoot, rel), srcExists)
+	if err != nil {
+		return err
+	}
+	dstObjs, err := s.list(s.dst, stdpath.Join(s.dstRoot, rel), dstExists)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(srcObjs)+len(dstObjs))
+	for name := range srcObjs {
+		names = append(names, name)
+	}
+	for name := range dstObjs {
+		if _, ok := srcObjs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := stdpath.Join(rel, name)
+		srcObj, dstObj := srcObjs[name], dstObjs[name]
+		isDir := (srcObj != nil && srcObj.IsDir()) || (dstObj != nil && dstObj.IsDir())
+		if matchSyncPattern(p, s.args.Exclude) || (!isDir && len(s.args.Include) > 0 && !matchSyncPattern(p, s.args.Include)) {
+			continue
+		}
+		switch {
+		case srcObj != nil && dstObj != nil && srcObj.IsDir() != dstObj.IsDir():
+			s.add(SyncOpConflict, p, srcObj, "file on one side, dir on the other")
+		case srcObj != nil && srcObj.IsDir():
+			if err = s.compareDir(p, true, dstObj != nil); err != nil {
+				return err
+			}
+		case dstObj != nil && dstObj.IsDir():
+			if err = s.compareMissingDir(p, dstObj); err != nil {
+				return err
+			}
+		case dstObj == nil:
+			s.add(SyncOpCopy, p, srcObj, "missing in dst")
+		case srcObj == nil:
+			switch s.args.Mode {
+			case model.SyncMirror:
+				s.add(SyncOpDelete, p, dstObj, "missing in src")
+			case model.SyncTwoWay:
+				s.add(SyncOpCopyBack, p, dstObj, "missing in src")
+			}
+		default:
+			s.compareFile(p, srcObj, dstObj)
+		}
+	}
+	return nil
+}
+
+// compareMissingDir handles a dir that only exists in the dst
+func (s *syncer) compareMissingDir(rel string, dstObj model.Obj) error {
+	switch s.args.Mode {
+	case model.SyncMirror:
+		// with include patterns the files not included must be kept
+		if len(s.args.Include) == 0 && len(s.args.Exclude) == 0 {
+			s.add(SyncOpDelete, rel, dstObj, "missing in src")
+			return nil
+		}
+		return s.compareDir(rel, false, true)
+	case model.SyncTwoWay:
+		return s.compareDir(rel, false, true)
+	}
+	return nil
+}
+
+func (s *syncer) compareFile(rel string, srcObj, dstObj model.Obj) {
+	// storages that don't report a modified time can't be compared by it
+	srcTime, dstTime := srcObj.ModTime(), dstObj.ModTime()
+	hasTime := !srcTime.IsZero() && !dstTime.IsZero()
+	srcNewer := hasTime && srcTime.Sub(dstTime) > syncMtimeTolerance
+	dstNewer := hasTime && dstTime.Sub(srcTime) > syncMtimeTolerance
+	var changed bool
+	var reason string
+	equal, hashOk := srcObj.GetHash().Compare(dstObj.GetHash())
+	if s.args.Compare == model.SyncCompareHash && hashOk {
+		changed, reason = !equal, "hash differs"
+	} else if srcObj.GetSize() != dstObj.GetSize() {
+		changed, reason = true, "size differs"
+	} else if s.args.Compare == model.SyncCompareHash {
+		// don't silently fall back to the modified time when asked to compare hashes
+		s.add(SyncOpSkip, rel, srcObj, "no common hash type to compare")
+		return
+	} else if s.args.Mode != model.SyncTwoWay && srcNewer {
+		// uploads usually get a new modified time, so only a newer src counts,
+		// two-way can't tell which side changed and relies on the size
+		changed, reason = true, "src is newer"
+	}
+	if !changed {
+		return
+	}
+	switch s.args.Mode {
+	case model.SyncMirror:
+		s.add(SyncOpCopy, rel, srcObj, reason)
+	case model.SyncUpdate:
+		if !dstNewer {
+			s.add(SyncOpCopy, rel, srcObj, reason)
+		}
+	case model.SyncTwoWay:
+		if dstNewer {
+			s.add(SyncOpCopyBack, rel, dstObj, reason+", dst is newer")
+		} else {
+			s.add(SyncOpCopy, rel, srcObj, reason)
+		}
+	}
+}
+
+func (s *syncer) apply(a SyncAction) error {
+	switch a.Op {
+	case SyncOpCopy:
+		return syncFile(s.ctx, s.src, s.dst, stdpath.Join(s.srcRoot, a.Path), stdpath.Join(s.dstRoot, stdpath.Dir(a.Path)))
+	case SyncOpCopyBack:
+		return syncFile(s.ctx, s.dst, s.src, stdpath.Join(s.dstRoot, a.Path), stdpath.Join(s.srcRoot, stdpath.Dir(a.Path)))
+	case SyncOpDelete:
+		// through fs so the deleted files go to the trash of the dst if enabled
+		return Remove(s.ctx, stdpath.Join(s.args.DstPath, a.Path))
+	}
+	return nil
+}
+
+func syncFile(ctx context.Context, srcStorage, dstStorage driver.Driver, srcFilePath, dstDirPath string) error {
+	srcFile, err := op.Get(ctx, srcStorage, srcFilePath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] file", srcFilePath)
+	}
+	if err = putFileBetween2Storages(ctx, srcStorage, dstStorage, srcFile, srcFilePath, dstDirPath, nil); err != nil {
+		return err
+	}
+	return op.VerifyHash(ctx, dstStorage, stdpath.Join(dstDirPath, srcFile.GetName()), srcFile.GetHash())
+}
+
+// matchSyncPattern reports whether the relative path or its name matches one of the glob patterns
+func matchSyncPattern(rel string, patterns []string) bool {
+	name := stdpath.Base(rel)
+	for _, p := range patterns {
+		if ok, _ := stdpath.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := stdpath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package fs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+var (
+	syncCronsMu sync.Mutex
+	syncCrons   = make(map[uint]*cron.Cron)
+)
+
+// StartSyncJobs schedules all enabled sync jobs
+func StartSyncJobs() {
+	jobs, err := op.GetEnabledSyncJobs()
+	if err != nil {
+		log.Errorf("failed get sync jobs: %+v", err)
+		return
+	}
+	for i := range jobs {
+		ScheduleSyncJob(&jobs[i])
+	}
+}
+
+// ScheduleSyncJob (re)starts running the job with its interval, disabled and manual ones are stopped
+func ScheduleSyncJob(j *model.SyncJob) {
+	syncCronsMu.Lock()
+	defer syncCronsMu.Unlock()
+	if c, ok := syncCrons[j.ID]; ok {
+		c.Stop()
+		delete(syncCrons, j.ID)
+	}
+	if j.Disabled || j.Interval <= 0 {
+		return
+	}
+	id := j.ID
+	c := cron.NewCron(time.Duration(j.Interval) * time.Minute)
+	c.Do(func() {
+		j, err := op.GetSyncJobById(id)
+		if err != nil {
+			log.Errorf("failed get sync job %d: %+v", id, err)
+			return
+		}
+		if _, err = RunSyncJob(context.Background(), j); err != nil {
+			log.Warnf("failed run sync job [%s]: %+v", j.Name, err)
+		}
+	})
+	syncCrons[id] = c
+}
+
+// UnscheduleSyncJob stops running the job periodically
+func UnscheduleSyncJob(id uint) {
+	syncCronsMu.Lock()
+	defer syncCronsMu.Unlock()
+	if c, ok := syncCrons[id]; ok {
+		c.Stop()
+		delete(syncCrons, id)
+	}
+}
+
+// SyncJobArgs returns the sync args of the job
+func SyncJobArgs(j *model.SyncJob) SyncArgs {
+	return SyncArgs{
+		SrcPath: j.SrcPath,
+		DstPath: j.DstPath,
+		Mode:    j.Mode,
+		Compare: j.Compare,
+		Include: op.SplitPatterns(j.Include),
+		Exclude: op.SplitPatterns(j.Exclude),
+	}
+}
+
+// RunSyncJob adds a sync task of the job as admin, unless the last one is still undone
+func RunSyncJob(ctx context.Context, j *model.SyncJob) (*SyncTask, error) {
+	if t, ok := SyncTaskManager.GetByID(j.LastTaskID); ok {
+		switch t.GetState() {
+		case tache.StateSucceeded, tache.StateCanceled, tache.StateFailed:
+		default:
+			return nil, errors.Errorf("the last task %s of the sync job is undone", j.LastTaskID)
+		}
+	}
+	t, err := func() (*SyncTask, error) {
+		admin, err := op.GetAdmin()
+		if err != nil {
+			return nil, err
+		}
+		return Sync(context.WithValue(ctx, "user", admin), SyncJobArgs(j), j.ID)
+	}()
+	j.LastRun = time.Now()
+	j.LastError = ""
+	if err != nil {
+		j.LastError = err.Error()
+	} else {
+		j.LastTaskID = t.GetID()
+	}
+	if err := op.UpdateSyncJobRun(j); err != nil {
+		log.Errorf("failed update sync job [%s]: %+v", j.Name, err)
+	}
+	return t, err
+}
@@ -0,0 +1,117 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestPlanSync(t *testing.T) {
+	srcRoot := mountLocal(t, model.Storage{MountPath: "/sync_src"})
+	dstRoot := mountLocal(t, model.Storage{MountPath: "/sync_dst"})
+	old, now := time.Now().Add(-time.Hour), time.Now()
+	writeFiles(t, srcRoot, map[string]string{
+		"same.txt":        "same",
+		"new.txt":         "new",
+		"changed.txt":     "changed",
+		"newer.txt":       "v2",
+		"dir/a.txt":       "a",
+		"dir/skip.tmp":    "tmp",
+		"conflict":        "file",
+		"excluded/b.txt":  "b",
+		"only_in_src.txt": "src",
+	}, old)
+	writeFiles(t, dstRoot, map[string]string{
+		"same.txt":         "same",
+		"changed.txt":      "changed!",
+		"conflict/c.txt":   "c",
+		"only_in_dst.txt":  "dst",
+		"gone/d.txt":       "d",
+		"excluded/old.txt": "old",
+	}, old)
+	writeFiles(t, dstRoot, map[string]string{"newer.txt": "v1"}, old.Add(-time.Hour))
+	writeFiles(t, srcRoot, map[string]string{"dst_newer.txt": "v1"}, old)
+	writeFiles(t, dstRoot, map[string]string{"dst_newer.txt": "v22"}, now)
+
+	args := SyncArgs{SrcPath: "/sync_src", DstPath: "/sync_dst", Exclude: []string{"*.tmp", "excluded"}}
+	names := func(actions []SyncAction) map[string]string {
+		m := make(map[string]string)
+		for _, a := range actions {
+			m[a.Path] = a.Op
+		}
+		return m
+	}
+	tests := []struct {
+		mode string
+		want map[string]string
+	}{
+		{model.SyncMirror, map[string]string{
+			"changed.txt": SyncOpCopy, "conflict": SyncOpConflict, "dir/a.txt": SyncOpCopy,
+			"dst_newer.txt": SyncOpCopy, "gone/d.txt": SyncOpDelete, "new.txt": SyncOpCopy,
+			"newer.txt": SyncOpCopy, "only_in_dst.txt": SyncOpDelete, "only_in_src.txt": SyncOpCopy,
+		}},
+		{model.SyncUpdate, map[string]string{
+			"changed.txt": SyncOpCopy, "conflict": SyncOpConflict, "dir/a.txt": SyncOpCopy,
+			"new.txt": SyncOpCopy, "newer.txt": SyncOpCopy, "only_in_src.txt": SyncOpCopy,
+		}},
+		{model.SyncTwoWay, map[string]string{
+			"changed.txt": SyncOpCopy, "conflict": SyncOpConflict, "dir/a.txt": SyncOpCopy,
+			// newer.txt has the same size on both sides, two-way doesn't trust the modified time
+			"dst_newer.txt": SyncOpCopyBack, "gone/d.txt": SyncOpCopyBack, "new.txt": SyncOpCopy,
+			"only_in_dst.txt": SyncOpCopyBack, "only_in_src.txt": SyncOpCopy,
+		}},
+	}
+	for _, tt := range tests {
+		args.Mode = tt.mode
+		actions, err := PlanSync(context.Background(), args)
+		if err != nil {
+			t.Fatalf("%s: %+v", tt.mode, err)
+		}
+		if got := names(actions); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %v\nwant %v", tt.mode, got, tt.want)
+		}
+	}
+
+	// the local driver reports no hash, files of the same size can't be compared by hash
+	args.Mode, args.Compare = model.SyncMirror, model.SyncCompareHash
+	actions, err := PlanSync(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := names(actions)
+	if got["same.txt"] != SyncOpSkip || got["newer.txt"] != SyncOpSkip || got["changed.txt"] != SyncOpCopy {
+		t.Errorf("hash compare got %v", got)
+	}
+
+	args.Compare = ""
+	s, err := newSyncer(context.Background(), &args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, err = s.plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range actions {
+		if err = s.apply(a); err != nil {
+			t.Fatalf("%s %s: %+v", a.Op, a.Path, err)
+		}
+	}
+	if actions, err = PlanSync(context.Background(), args); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(actions); !reflect.DeepEqual(got, map[string]string{"conflict": SyncOpConflict}) {
+		t.Errorf("after mirror got %v", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dstRoot, "dir/a.txt")); string(got) != "a" {
+		t.Errorf("dir/a.txt is %q", got)
+	}
+	if _, err = os.Stat(filepath.Join(dstRoot, "excluded/old.txt")); err != nil {
+		t.Errorf("excluded file should be kept: %v", err)
+	}
+}
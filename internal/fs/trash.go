@@ -0,0 +1,288 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const TrashDirName = ".alist-trash"
+
+// trashRoot returns the full path of the trash dir of the storage
+func trashRoot(storage driver.Driver) string {
+	if storage.GetStorage().TrashPath != "" {
+		return utils.FixAndCleanPath(storage.GetStorage().TrashPath)
+	}
+	return stdpath.Join(utils.GetActualMountPath(storage.GetStorage().MountPath), TrashDirName)
+}
+
+func isTrashEnabled(storage driver.Driver) bool {
+	return storage.GetStorage().EnableTrash && !storage.Config().NoUpload
+}
+
+// IsInTrash reports whether the path is in the trash dir of any storage
+func IsInTrash(path string) bool {
+	for _, storage := range op.GetAllStorages() {
+		if isTrashEnabled(storage) && utils.IsSubPath(trashRoot(storage), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInternalAccess hides the trash and versions dirs from users other than admins,
+// they hold objects removed and overwritten by all users. Calls without a user such as tasks are allowed
+func checkInternalAccess(ctx context.Context, paths ...string) error {
+	user, _ := ctx.Value("user").(*model.User)
+	if user == nil || user.IsAdmin() {
+		return nil
+	}
+	for _, path := range paths {
+		if IsInTrash(path) || IsInVersions(path) {
+			return errors.WithStack(errs.ObjectNotFound)
+		}
+	}
+	return nil
+}
+
+// moveToTrash moves the object to the trash of the storage instead of removing it
+func moveToTrash(ctx context.Context, storage driver.Driver, path, actualPath string) error {
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return nil
+		}
+		return errors.WithMessage(err, "failed get object")
+	}
+	// every removed object has its own dir in trash to avoid name conflicts
+	dstDir := stdpath.Join(trashRoot(storage), fmt.Sprintf("%d", time.Now().UnixNano()))
+	item := &model.TrashItem{
+		Name:         obj.GetName(),
+		OriginalPath: path,
+		TrashPath:    stdpath.Join(dstDir, obj.GetName()),
+		Size:         obj.GetSize(),
+		IsDir:        obj.IsDir(),
+		RemovedAt:    time.Now(),
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		item.UserId = user.ID
+	}
+	if err = moveToTrashDir(ctx, storage, actualPath, dstDir, item); err != nil {
+		return errors.WithMessage(err, "failed move object to trash")
+	}
+	return nil
+}
+
+// saveTrashItem records the object once it's in trash
+func saveTrashItem(item *model.TrashItem) error {
+	// the user quota still counts it until it's removed from trash
+	moveUploads(item.OriginalPath, item.TrashPath)
+	moveVersions(item.OriginalPath, item.TrashPath)
+	return op.CreateTrashItem(item)
+}
+
+// moveToTrashDir moves the object to the trash dir and records it, a trash in another storage
+// is moved to by a copy task so the remove doesn't wait for the copy, the task records it when succeeded.
+// Without task managers, such as in the fs commands, it's moved directly
+func moveToTrashDir(ctx context.Context, storage driver.Driver, actualPath, dstDirPath string, item *model.TrashItem) error {
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get trash storage")
+	}
+	if storage.GetStorage() == dstStorage.GetStorage() {
+		if err = op.MakeDir(ctx, dstStorage, dstDirActualPath); err != nil {
+			return errors.WithMessagef(err, "failed make dir [%s]", dstDirPath)
+		}
+		if err = op.Move(ctx, storage, actualPath, dstDirActualPath); err != nil {
+			return err
+		}
+		return saveTrashItem(item)
+	}
+	if ctx.Value(conf.NoTaskKey) != nil {
+		// no task manager is running, so move it here
+		if err = op.MakeDir(ctx, dstStorage, dstDirActualPath); err != nil {
+			return errors.WithMessagef(err, "failed make dir [%s]", dstDirPath)
+		}
+		if err = copyDirectly(ctx, storage, actualPath, dstStorage, dstDirActualPath); err != nil {
+			return err
+		}
+		if err = op.Remove(ctx, storage, actualPath); err != nil {
+			return err
+		}
+		return saveTrashItem(item)
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	CopyTaskManager.Add(&CopyTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		srcStorage:   storage,
+		dstStorage:   dstStorage,
+		SrcObjPath:   actualPath,
+		DstDirPath:   dstDirActualPath,
+		SrcStorageMp: storage.GetStorage().MountPath,
+		DstStorageMp: dstStorage.GetStorage().MountPath,
+		Move:         true,
+		TrashItem:    item,
+	})
+	return nil
+}
+
+// moveDirectly moves the object to dstDirPath and returns after finish,
+// objects between two storages are copied then removed
+func moveDirectly(ctx context.Context, srcPath, dstDirPath string) error {
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	if err = op.MakeDir(ctx, dstStorage, dstDirActualPath); err != nil {
+		return errors.WithMessagef(err, "failed make dir [%s]", dstDirPath)
+	}
+	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+		return op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath)
+	}
+	if err = copyDirectly(ctx, srcStorage, srcActualPath, dstStorage, dstDirActualPath); err != nil {
+		return err
+	}
+	return op.Remove(ctx, srcStorage, srcActualPath)
+}
+
+// copyDirectly copies the object between two storages recursively and returns after finish
+func copyDirectly(ctx context.Context, srcStorage driver.Driver, srcObjPath string, dstStorage driver.Driver, dstDirPath string) error {
+	srcObj, err := op.Get(ctx, srcStorage, srcObjPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] file", srcObjPath)
+	}
+	if srcObj.IsDir() {
+		dstObjPath := stdpath.Join(dstDirPath, srcObj.GetName())
+		if err = op.MakeDir(ctx, dstStorage, dstObjPath); err != nil {
+			return errors.WithMessagef(err, "failed make dir [%s]", dstObjPath)
+		}
+		objs, err := op.List(ctx, srcStorage, srcObjPath, model.ListArgs{})
+		if err != nil {
+			return errors.WithMessagef(err, "failed list src [%s] objs", srcObjPath)
+		}
+		for _, obj := range objs {
+			if utils.IsCanceled(ctx) {
+				return ctx.Err()
+			}
+			if err = copyDirectly(ctx, srcStorage, stdpath.Join(srcObjPath, obj.GetName()), dstStorage, dstObjPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	link, _, err := op.Link(ctx, srcStorage, srcObjPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] link", srcObjPath)
+	}
+	fs := stream.FileStream{
+		Obj: srcObj,
+		Ctx: ctx,
+	}
+	ss, err := stream.NewSeekableStream(fs, link)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] stream", srcObjPath)
+	}
+	return op.Put(ctx, dstStorage, dstDirPath, ss, nil, false)
+}
+
+// RestoreTrashItem moves the object in trash back to its original path
+func RestoreTrashItem(ctx context.Context, item *model.TrashItem) error {
+	if _, err := Get(ctx, item.OriginalPath, &GetArgs{NoLog: true}); err == nil {
+		return errors.Errorf("object already exists at [%s]", item.OriginalPath)
+	}
+	err := moveDirectly(ctx, item.TrashPath, stdpath.Dir(item.OriginalPath))
+	if err != nil {
+		log.Errorf("failed restore %s: %+v", item.TrashPath, err)
+		return err
+	}
+	moveUploads(item.TrashPath, item.OriginalPath)
+	moveVersions(item.TrashPath, item.OriginalPath)
+	removeTrashDir(ctx, item)
+	return op.DeleteTrashItemById(item.ID)
+}
+
+// DeleteTrashItem removes the object in trash permanently
+func DeleteTrashItem(ctx context.Context, item *model.TrashItem) error {
+	if err := removeTrashDir(ctx, item); err != nil {
+		return err
+	}
+	releaseUploads(item.TrashPath)
+	deleteVersions(ctx, item.TrashPath)
+	return op.DeleteTrashItemById(item.ID)
+}
+
+func removeTrashDir(ctx context.Context, item *model.TrashItem) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Dir(item.TrashPath))
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	err = op.Remove(ctx, storage, actualPath)
+	if err != nil {
+		log.Errorf("failed remove %s: %+v", item.TrashPath, err)
+	}
+	return err
+}
+
+// EmptyTrash removes all objects in trash of the user permanently, userId = 0 means all users
+func EmptyTrash(ctx context.Context, userId uint) error {
+	items, _, err := op.GetTrashItems(userId, 1, model.MaxInt)
+	if err != nil {
+		return err
+	}
+	var errMsgs []string
+	for i := range items {
+		if err := DeleteTrashItem(ctx, &items[i]); err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("%s: %s", items[i].OriginalPath, err.Error()))
+		}
+	}
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "\n"))
+	}
+	return nil
+}
+
+// PurgeExpiredTrash removes objects that have been in trash longer than
+// the retention of their original storage
+func PurgeExpiredTrash(ctx context.Context) {
+	items, err := op.GetTrashItemsBefore(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		log.Errorf("failed get trash items: %+v", err)
+		return
+	}
+	for i := range items {
+		item := &items[i]
+		storage, _, err := op.GetStorageAndActualPath(item.OriginalPath)
+		if err != nil {
+			continue
+		}
+		retention := storage.GetStorage().TrashRetention
+		if retention <= 0 || time.Since(item.RemovedAt) < time.Duration(retention)*24*time.Hour {
+			continue
+		}
+		if err = DeleteTrashItem(ctx, item); err != nil {
+			log.Errorf("failed purge trash item %s: %+v", item.TrashPath, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestTrashAccess(t *testing.T) {
+	root := mountLocal(t, model.Storage{
+		MountPath: "/trash_test",
+		Trash:     model.Trash{EnableTrash: true},
+	})
+	writeFiles(t, root, map[string]string{"a.txt": "a"}, time.Now())
+	if err := Remove(context.Background(), "/trash_test/a.txt"); err != nil {
+		t.Fatalf("remove: %+v", err)
+	}
+	items, _, err := op.GetTrashItems(0, 1, model.MaxInt)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one trash item, got %v %+v", items, err)
+	}
+	trashPath := items[0].TrashPath
+	if !IsInTrash(trashPath) {
+		t.Fatalf("%s should be in trash", trashPath)
+	}
+	user := context.WithValue(context.Background(), "user", &model.User{Role: model.GENERAL})
+	if _, err = Get(user, trashPath, &GetArgs{NoLog: true}); !errs.IsObjectNotFound(err) {
+		t.Errorf("users should not get objects in trash, got %v", err)
+	}
+	if _, err = List(user, "/trash_test/"+TrashDirName, &ListArgs{NoLog: true}); !errs.IsObjectNotFound(err) {
+		t.Errorf("users should not list the trash, got %v", err)
+	}
+	trashDir := "/trash_test/" + TrashDirName
+	if err = Remove(user, trashDir); !errs.IsObjectNotFound(err) {
+		t.Errorf("users should not remove the trash, got %v", err)
+	}
+	if err = Rename(user, trashPath, "b.txt"); !errs.IsObjectNotFound(err) {
+		t.Errorf("users should not rename objects in trash, got %v", err)
+	}
+	writeFiles(t, root, map[string]string{"c.txt": "c"}, time.Now())
+	if err = Move(user, "/trash_test/c.txt", trashDir); !errs.IsObjectNotFound(err) {
+		t.Errorf("users should not move objects into trash, got %v", err)
+	}
+	if _, err = Copy(user, trashPath, "/trash_test"); !errs.IsObjectNotFound(err) {
+		t.Errorf("users should not copy objects out of trash, got %v", err)
+	}
+	admin := context.WithValue(context.Background(), "user", &model.User{Role: model.ADMIN})
+	if _, err = Get(admin, trashPath, &GetArgs{NoLog: true}); err != nil {
+		t.Errorf("admins should get objects in trash: %+v", err)
+	}
+}
+
+func TestTrashInOtherStorageWithoutTask(t *testing.T) {
+	trashRoot := mountLocal(t, model.Storage{MountPath: "/trash_other"})
+	root := mountLocal(t, model.Storage{
+		MountPath: "/trash_src",
+		Trash:     model.Trash{EnableTrash: true, TrashPath: "/trash_other/bin"},
+	})
+	writeFiles(t, root, map[string]string{"a.txt": "a"}, time.Now())
+	ctx := context.WithValue(context.Background(), conf.NoTaskKey, struct{}{})
+	if err := Remove(ctx, "/trash_src/a.txt"); err != nil {
+		t.Fatalf("remove: %+v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.txt")); !os.IsNotExist(err) {
+		t.Errorf("a.txt should be removed, got %v", err)
+	}
+	items, _, err := op.GetTrashItems(0, 1, model.MaxInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.OriginalPath == "/trash_src/a.txt" {
+			if _, err = os.Stat(filepath.Join(trashRoot, strings.TrimPrefix(item.TrashPath, "/trash_other"))); err != nil {
+				t.Errorf("a.txt should be in trash: %v", err)
+			}
+			return
+		}
+	}
+	t.Errorf("no trash item of a.txt in %+v", items)
+}
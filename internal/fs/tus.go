@@ -0,0 +1,57 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// tusLocks keeps the PATCH requests of an upload from writing at the same time
+var tusLocks generic_sync.MapOf[string, *sync.Mutex]
+
+// TusTempDir returns the dir where the chunks of tus uploads are staged
+func TusTempDir() string {
+	return filepath.Join(conf.Conf.TempDir, "tus")
+}
+
+// TryLockTusUpload locks the upload for writing, reports false if it's being written
+func TryLockTusUpload(id string) (*sync.Mutex, bool) {
+	mu, _ := tusLocks.LoadOrStore(id, &sync.Mutex{})
+	return mu, mu.TryLock()
+}
+
+// ForgetTusUpload deletes the record and the lock of the upload, the staged file is left to the caller
+func ForgetTusUpload(id string) error {
+	tusLocks.Delete(id)
+	return op.DeleteTusUploadById(id)
+}
+
+// DeleteTusUpload deletes the upload with its staged file
+func DeleteTusUpload(u *model.TusUpload) error {
+	if err := os.Remove(u.TempFile); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return ForgetTusUpload(u.ID)
+}
+
+// PurgeExpiredTusUploads removes tus uploads which have not been updated for a day
+func PurgeExpiredTusUploads() {
+	uploads, err := op.GetTusUploadsBefore(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		log.Errorf("failed get tus uploads: %+v", err)
+		return
+	}
+	for i := range uploads {
+		if err := DeleteTusUpload(&uploads[i]); err != nil {
+			log.Errorf("failed delete tus upload %s: %+v", uploads[i].ID, err)
+		}
+	}
+}
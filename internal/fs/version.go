@@ -0,0 +1,237 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const VersionsDirName = ".alist-versions"
+
+func isVersioningEnabled(storage driver.Driver) bool {
+	return storage.GetStorage().EnableVersions && !storage.Config().NoUpload
+}
+
+// canMove reports whether the driver moves files, versions are kept by copy on drivers that can't
+func canMove(storage driver.Driver) bool {
+	switch storage.(type) {
+	case driver.Move, driver.MoveResult:
+		return true
+	}
+	return false
+}
+
+func canCopy(storage driver.Driver) bool {
+	switch storage.(type) {
+	case driver.Copy, driver.CopyResult:
+		return true
+	}
+	return false
+}
+
+// versionsRoot returns the actual path of the versions dir of the storage
+func versionsRoot() string {
+	return "/" + VersionsDirName
+}
+
+// IsInVersions reports whether the path is in the versions dir of its storage
+func IsInVersions(path string) bool {
+	_, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return false
+	}
+	return utils.IsSubPath(versionsRoot(), actualPath)
+}
+
+// putWithVersion keeps the existing file as a version before put,
+// and moves it back if put failed
+func putWithVersion(ctx context.Context, storage driver.Driver, dstDirActualPath string, file model.FileStreamer, up driver.UpdateProgress, lazyCache ...bool) error {
+	if !isVersioningEnabled(storage) {
+		return op.Put(ctx, storage, dstDirActualPath, file, up, lazyCache...)
+	}
+	v, err := saveVersion(ctx, storage, stdpath.Join(dstDirActualPath, file.GetName()))
+	if err != nil {
+		return errors.WithMessage(err, "failed save version")
+	}
+	err = op.Put(ctx, storage, dstDirActualPath, file, up, lazyCache...)
+	if err != nil {
+		// a copied version leaves the file in place, op.Put keeps it if put failed
+		if v != nil && canMove(storage) {
+			if e := restoreVersion(ctx, storage, v); e != nil {
+				log.Errorf("failed recover version of %s: %+v", v.Path, e)
+			}
+		}
+		return err
+	}
+	if v != nil {
+		pruneVersions(ctx, storage, v.Path)
+	}
+	return nil
+}
+
+// saveVersion moves the existing file to the versions dir, or copies it if the driver can't move,
+// returns nil if there is nothing to keep
+func saveVersion(ctx context.Context, storage driver.Driver, actualPath string) (*model.FileVersion, error) {
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if obj.IsDir() || obj.GetSize() == 0 {
+		return nil, nil
+	}
+	if !canMove(storage) && !canCopy(storage) {
+		log.Warnf("storage [%s] can neither move nor copy, no version of %s is kept", storage.GetStorage().MountPath, actualPath)
+		return nil, nil
+	}
+	dstDir := stdpath.Join(versionsRoot(), fmt.Sprintf("%d", time.Now().UnixNano()))
+	if err = op.MakeDir(ctx, storage, dstDir); err != nil {
+		return nil, errors.WithMessagef(err, "failed make dir [%s]", dstDir)
+	}
+	if canMove(storage) {
+		err = op.Move(ctx, storage, actualPath, dstDir)
+	} else {
+		err = op.Copy(ctx, storage, actualPath, dstDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	mountPath := utils.GetActualMountPath(storage.GetStorage().MountPath)
+	v := &model.FileVersion{
+		Path:        stdpath.Join(mountPath, actualPath),
+		VersionPath: stdpath.Join(mountPath, dstDir, obj.GetName()),
+		Size:        obj.GetSize(),
+		Modified:    obj.ModTime(),
+	}
+	return v, op.CreateFileVersion(v)
+}
+
+// moveVersions keeps the versions with the file after it's moved or renamed
+func moveVersions(oldPath, newPath string) {
+	if err := op.MoveFileVersions(utils.FixAndCleanPath(oldPath), utils.FixAndCleanPath(newPath)); err != nil {
+		log.Errorf("failed move versions of %s: %+v", oldPath, err)
+	}
+}
+
+// deleteVersions removes the versions of the removed file, or of the files in the removed dir
+func deleteVersions(ctx context.Context, path string) {
+	versions, err := op.GetFileVersionsUnder(utils.FixAndCleanPath(path))
+	if err != nil {
+		log.Errorf("failed get versions of %s: %+v", path, err)
+		return
+	}
+	for i := range versions {
+		if err := DeleteVersion(ctx, &versions[i]); err != nil {
+			log.Errorf("failed delete version %s: %+v", versions[i].VersionPath, err)
+		}
+	}
+}
+
+func toActualPath(storage driver.Driver, path string) string {
+	return utils.FixAndCleanPath(strings.TrimPrefix(path, utils.GetActualMountPath(storage.GetStorage().MountPath)))
+}
+
+// restoreVersion moves the version back to its path and removes the record
+func restoreVersion(ctx context.Context, storage driver.Driver, v *model.FileVersion) error {
+	versionActualPath := toActualPath(storage, v.VersionPath)
+	actualPath := toActualPath(storage, v.Path)
+	// the current file is still there if it was copied as a version or not kept as empty
+	if err := op.Remove(ctx, storage, actualPath); err != nil {
+		return err
+	}
+	if canMove(storage) {
+		if err := op.Move(ctx, storage, versionActualPath, stdpath.Dir(actualPath)); err != nil {
+			return err
+		}
+	} else if err := op.Copy(ctx, storage, versionActualPath, stdpath.Dir(actualPath)); err != nil {
+		return err
+	}
+	if err := op.Remove(ctx, storage, stdpath.Dir(versionActualPath)); err != nil {
+		log.Errorf("failed remove version dir of %s: %+v", v.VersionPath, err)
+	}
+	return op.DeleteFileVersionById(v.ID)
+}
+
+// pruneVersions removes the oldest versions of the file exceeding the limit of the storage
+func pruneVersions(ctx context.Context, storage driver.Driver, path string) {
+	max := storage.GetStorage().MaxVersions
+	if max <= 0 {
+		return
+	}
+	versions, err := op.GetFileVersions(path)
+	if err != nil {
+		log.Errorf("failed get versions of %s: %+v", path, err)
+		return
+	}
+	for i := max; i < len(versions); i++ {
+		if err := DeleteVersion(ctx, &versions[i]); err != nil {
+			log.Errorf("failed prune version %s: %+v", versions[i].VersionPath, err)
+		}
+	}
+}
+
+// RestoreVersion replaces the file with the version, the current content is kept as a new version
+func RestoreVersion(ctx context.Context, v *model.FileVersion) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(v.Path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	current, err := saveVersion(ctx, storage, actualPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed save current version")
+	}
+	if err = restoreVersion(ctx, storage, v); err != nil {
+		return errors.WithMessage(err, "failed restore version")
+	}
+	if current != nil {
+		pruneVersions(ctx, storage, v.Path)
+	}
+	return nil
+}
+
+// DeleteVersion removes the version permanently
+func DeleteVersion(ctx context.Context, v *model.FileVersion) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Dir(v.VersionPath))
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if err = op.Remove(ctx, storage, actualPath); err != nil {
+		return err
+	}
+	return op.DeleteFileVersionById(v.ID)
+}
+
+// PurgeExpiredVersions removes versions older than the retention of their storage
+func PurgeExpiredVersions(ctx context.Context) {
+	versions, err := op.GetFileVersionsBefore(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		log.Errorf("failed get file versions: %+v", err)
+		return
+	}
+	for i := range versions {
+		v := &versions[i]
+		storage, _, err := op.GetStorageAndActualPath(v.Path)
+		if err != nil {
+			continue
+		}
+		retention := storage.GetStorage().VersionRetention
+		if retention <= 0 || time.Since(v.CreatedAt) < time.Duration(retention)*24*time.Hour {
+			continue
+		}
+		if err = DeleteVersion(ctx, v); err != nil {
+			log.Errorf("failed purge version %s: %+v", v.VersionPath, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package fs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+func TestVersionsFollowRename(t *testing.T) {
+	root := mountLocal(t, model.Storage{
+		MountPath:  "/version_test",
+		Versioning: model.Versioning{EnableVersions: true},
+	})
+	writeFiles(t, root, map[string]string{"d_1/a.txt": "v1", "dx1/a.txt": "other"}, time.Now())
+	for _, dir := range []string{"/version_test/d_1", "/version_test/dx1"} {
+		s := &stream.FileStream{
+			Obj:    &model.Object{Name: "a.txt", Size: 2, Modified: time.Now()},
+			Reader: strings.NewReader("v2"),
+		}
+		if err := PutDirectly(context.Background(), dir, s); err != nil {
+			t.Fatalf("put: %+v", err)
+		}
+	}
+	if err := Rename(context.Background(), "/version_test/d_1", "renamed"); err != nil {
+		t.Fatalf("rename: %+v", err)
+	}
+	if versions, _ := op.GetFileVersions("/version_test/renamed/a.txt"); len(versions) != 1 {
+		t.Errorf("versions should follow the rename, got %+v", versions)
+	}
+	// _ in the renamed dir must not match dx1
+	if versions, _ := op.GetFileVersions("/version_test/dx1/a.txt"); len(versions) != 1 {
+		t.Errorf("versions of other dirs should be kept, got %+v", versions)
+	}
+}
+
+func TestVersionsAccessAndRemove(t *testing.T) {
+	root := mountLocal(t, model.Storage{
+		MountPath:  "/version_remove_test",
+		Versioning: model.Versioning{EnableVersions: true},
+	})
+	writeFiles(t, root, map[string]string{"a.txt": "v1"}, time.Now())
+	s := &stream.FileStream{
+		Obj:    &model.Object{Name: "a.txt", Size: 2, Modified: time.Now()},
+		Reader: strings.NewReader("v2"),
+	}
+	if err := PutDirectly(context.Background(), "/version_remove_test", s); err != nil {
+		t.Fatalf("put: %+v", err)
+	}
+	versions, _ := op.GetFileVersions("/version_remove_test/a.txt")
+	if len(versions) != 1 {
+		t.Fatalf("expect 1 version, got %+v", versions)
+	}
+	user := context.WithValue(context.Background(), "user", &model.User{Role: model.GENERAL})
+	if _, err := Get(user, versions[0].VersionPath, &GetArgs{NoLog: true}); err == nil {
+		t.Errorf("general user should not get the version")
+	}
+	if err := Remove(user, versions[0].VersionPath); err == nil {
+		t.Errorf("general user should not remove the version")
+	}
+	if err := Remove(context.Background(), "/version_remove_test/a.txt"); err != nil {
+		t.Fatalf("remove: %+v", err)
+	}
+	if versions, _ := op.GetFileVersions("/version_remove_test/a.txt"); len(versions) != 0 {
+		t.Errorf("versions should be removed with the file, got %+v", versions)
+	}
+}
+
+func TestRestoreVersionOverEmptyFile(t *testing.T) {
+	root := mountLocal(t, model.Storage{
+		MountPath:  "/version_empty_test",
+		Versioning: model.Versioning{EnableVersions: true},
+	})
+	writeFiles(t, root, map[string]string{"a.txt": "v1"}, time.Now())
+	s := &stream.FileStream{
+		Obj:    &model.Object{Name: "a.txt", Size: 0, Modified: time.Now()},
+		Reader: strings.NewReader(""),
+	}
+	if err := PutDirectly(context.Background(), "/version_empty_test", s); err != nil {
+		t.Fatalf("put: %+v", err)
+	}
+	versions, _ := op.GetFileVersions("/version_empty_test/a.txt")
+	if len(versions) != 1 {
+		t.Fatalf("expect 1 version, got %+v", versions)
+	}
+	if err := RestoreVersion(context.Background(), &versions[0]); err != nil {
+		t.Fatalf("restore: %+v", err)
+	}
+	obj, err := Get(context.Background(), "/version_empty_test/a.txt", &GetArgs{NoLog: true})
+	if err != nil || obj.GetSize() != 2 {
+		t.Errorf("expect the restored version, got %+v %+v", obj, err)
+	}
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+var (
+	checkerMu sync.Mutex
+	checker   *cron.Cron
+)
+
+func init() {
+	op.RegisterSettingItemHook(conf.StorageHealthCheck, func(item *model.SettingItem) error {
+		minutes, err := strconv.Atoi(item.Value)
+		if err != nil {
+			return err
+		}
+		start(minutes)
+		return nil
+	})
+}
+
+// Start starts checking health of storages with the interval in settings
+func Start() {
+	start(setting.GetInt(conf.StorageHealthCheck, 0))
+}
+
+func start(minutes int) {
+	checkerMu.Lock()
+	defer checkerMu.Unlock()
+	if checker != nil {
+		checker.Stop()
+		checker = nil
+	}
+	if minutes <= 0 {
+		return
+	}
+	checker = cron.NewCron(time.Duration(minutes) * time.Minute)
+	checker.Do(func() {
+		CheckAll(context.Background())
+	})
+}
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/webhook"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxHistory    = 50
+	probeTimeout  = 30 * time.Second
+	reinitBackoff = time.Minute
+	maxBackoff    = 6 * time.Hour
+)
+
+type Record struct {
+	Time    time.Time `json:"time"`
+	Latency int64     `json:"latency"` // milliseconds
+	Healthy bool      `json:"healthy"`
+	Error   string    `json:"error,omitempty"`
+}
+
+type Status struct {
+	ID         uint      `json:"id"`
+	MountPath  string    `json:"mount_path"`
+	Driver     string    `json:"driver"`
+	Status     string    `json:"status"`
+	Healthy    bool      `json:"healthy"`
+	LastCheck  time.Time `json:"last_check"`
+	Latency    int64     `json:"latency"`
+	LastError  string    `json:"last_error"`
+	Failures   int       `json:"failures"` // consecutive failed checks
+	NextReinit time.Time `json:"next_reinit"`
+	History    []Record  `json:"history"`
+
+	reinitAttempts int
+}
+
+var (
+	mu       sync.Mutex
+	statuses = map[uint]*Status{}
+)
+
+func getStatus(storage *model.Storage) *Status {
+	s, ok := statuses[storage.ID]
+	if !ok {
+		s = &Status{ID: storage.ID, Healthy: true}
+		statuses[storage.ID] = s
+	}
+	s.MountPath = storage.MountPath
+	s.Driver = storage.Driver
+	s.Status = storage.Status
+	return s
+}
+
+// GetAll returns copies of health status of all checked storages
+func GetAll() []Status {
+	mu.Lock()
+	defer mu.Unlock()
+	res := make([]Status, 0, len(statuses))
+	for _, s := range statuses {
+		res = append(res, copyStatus(s))
+	}
+	return res
+}
+
+// Get returns a copy of health status of the storage
+func Get(id uint) (Status, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := statuses[id]
+	if !ok {
+		return Status{}, false
+	}
+	return copyStatus(s), true
+}
+
+func copyStatus(s *Status) Status {
+	res := *s
+	res.History = append([]Record(nil), s.History...)
+	return res
+}
+
+// probe lists the root of the storage, which is cheap for most drivers
+func probe(ctx context.Context, storage driver.Driver) error {
+	if status := storage.GetStorage().Status; status != op.WORK {
+		return errors.New(status)
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	_, err := op.List(ctx, storage, "/", model.ListArgs{Refresh: true})
+	return err
+}
+
+// Check probes the storage and records the result, the storage is initialized again
+// on any failure, since most drivers don't tell an expired token from other errors.
+// The reinit backs off while it doesn't make the storage healthy
+func Check(ctx context.Context, storage driver.Driver) Status {
+	start := time.Now()
+	err := probe(ctx, storage)
+	if err != nil && !errors.Is(err, context.Canceled) && time.Now().After(nextReinit(storage)) {
+		newStorage, e := reinit(ctx, storage)
+		if e == nil {
+			storage = newStorage
+			start = time.Now()
+			err = probe(ctx, storage)
+		} else {
+			err = e
+		}
+		if err != nil {
+			backoffReinit(storage, err)
+		}
+	}
+	return record(storage, start, err)
+}
+
+func nextReinit(storage driver.Driver) time.Time {
+	mu.Lock()
+	defer mu.Unlock()
+	return getStatus(storage.GetStorage()).NextReinit
+}
+
+func reinit(ctx context.Context, storage driver.Driver) (driver.Driver, error) {
+	log.Infof("reinit storage [%s] after failed health check", storage.GetStorage().MountPath)
+	return op.ReinitStorage(ctx, storage)
+}
+
+// backoffReinit delays the next reinit of the storage which is still failing after a reinit
+func backoffReinit(storage driver.Driver, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	s := getStatus(storage.GetStorage())
+	backoff := reinitBackoff << s.reinitAttempts
+	if backoff > maxBackoff || backoff <= 0 {
+		backoff = maxBackoff
+	} else {
+		s.reinitAttempts++
+	}
+	s.NextReinit = time.Now().Add(backoff)
+	log.Warnf("storage [%s] still fails after reinit, next try after %s: %+v", storage.GetStorage().MountPath, backoff, err)
+}
+
+func record(storage driver.Driver, start time.Time, err error) Status {
+	mu.Lock()
+	s := getStatus(storage.GetStorage())
+	r := Record{
+		Time:    time.Now(),
+		Latency: time.Since(start).Milliseconds(),
+		Healthy: err == nil,
+	}
+	wasHealthy := s.Healthy
+	s.LastCheck = r.Time
+	s.Latency = r.Latency
+	s.Healthy = r.Healthy
+	if err != nil {
+		r.Error = err.Error()
+		s.LastError = r.Error
+		s.Failures++
+	} else {
+		s.Failures = 0
+		s.reinitAttempts = 0
+		s.NextReinit = time.Time{}
+	}
+	s.History = append(s.History, r)
+	if len(s.History) > maxHistory {
+		s.History = s.History[len(s.History)-maxHistory:]
+	}
+	res := copyStatus(s)
+	mu.Unlock()
+
+	data := webhook.StorageData{
+		ID:        storage.GetStorage().ID,
+		MountPath: storage.GetStorage().MountPath,
+		Driver:    storage.GetStorage().Driver,
+		Status:    storage.GetStorage().Status,
+	}
+	if err != nil {
+		// keep the balancer away from the storage until it recovers
+		op.EjectStorage(storage)
+		if wasHealthy {
+			data.Error = r.Error
+			webhook.Emit(webhook.StorageUnhealthy, data)
+		}
+	} else if !wasHealthy {
+		webhook.Emit(webhook.StorageRecovered, data)
+	}
+	return res
+}
+
+// CheckAll probes all enabled storages concurrently
+func CheckAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, storage := range op.GetAllStorages() {
+		if storage.GetStorage().Disabled {
+			continue
+		}
+		wg.Add(1)
+		go func(storage driver.Driver) {
+			defer wg.Done()
+			Check(ctx, storage)
+		}(storage)
+	}
+	wg.Wait()
+}
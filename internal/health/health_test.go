@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/testutil"
+	pkgerr "github.com/pkg/errors"
+)
+
+func init() {
+	testutil.InitDB()
+}
+
+func TestIsUnauthorized(t *testing.T) {
+	for err, expected := range map[error]bool{
+		errs.NewErr(errs.Unauthorized, "code: 401"):                     true,
+		pkgerr.WithMessage(errs.NewErr(errs.Unauthorized, "x"), "list"): true,
+		fmt.Errorf("failed list: %w", errs.Unauthorized):                true,
+		errors.New("refresh token is invalid"):                          false,
+		errors.New("context deadline exceeded"):                         false,
+	} {
+		if errs.IsUnauthorized(err) != expected {
+			t.Errorf("IsUnauthorized(%q) should be %v", err, expected)
+		}
+	}
+}
+
+func TestCheckReinit(t *testing.T) {
+	root := t.TempDir()
+	if _, err := op.CreateStorage(context.Background(), model.Storage{
+		MountPath: "/health",
+		Driver:    "Local",
+		Addition:  `{"root_folder_path":"` + root + `"}`,
+	}); err != nil {
+		t.Fatalf("create storage: %+v", err)
+	}
+	if err := os.Remove(root); err != nil {
+		t.Fatal(err)
+	}
+	storage, _ := op.GetStorageByMountPath("/health")
+	// any failure of a working storage reinits it, the failed reinit backs off
+	s := Check(context.Background(), storage)
+	if s.Healthy || !s.NextReinit.After(time.Now()) {
+		t.Fatalf("expected unhealthy with a backoff, got %+v", s)
+	}
+	if err := os.Mkdir(root, 0777); err != nil {
+		t.Fatal(err)
+	}
+	storage, _ = op.GetStorageByMountPath("/health")
+	if s = Check(context.Background(), storage); s.Healthy {
+		t.Fatalf("reinit should wait for the backoff, got %+v", s)
+	}
+	mu.Lock()
+	statuses[s.ID].NextReinit = time.Time{}
+	mu.Unlock()
+	if s = Check(context.Background(), storage); !s.Healthy || !s.NextReinit.IsZero() {
+		t.Fatalf("expected healthy after reinit, got %+v", s)
+	}
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "alist"
+
+const (
+	ProtocolHTTP   = "http"
+	ProtocolWebDAV = "webdav"
+	ProtocolFTP    = "ftp"
+	ProtocolSFTP   = "sftp"
+	ProtocolS3     = "s3"
+)
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_total",
+		Help:      "Total number of requests by protocol, route, method and status.",
+	}, []string{"protocol", "route", "method", "status"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_duration_seconds",
+		Help:      "Request latency by protocol, route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"protocol", "route", "method"})
+	proxyBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "proxy_bytes_total",
+		Help:      "Total bytes sent to clients through the proxy.",
+	})
+	listCache = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "list_cache_total",
+		Help:      "List cache lookups by result (hit or miss).",
+	}, []string{"result"})
+	driverCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "driver_call_duration_seconds",
+		Help:      "Driver call latency by storage, driver and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"storage", "driver", "method"})
+	driverCallErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "driver_call_errors_total",
+		Help:      "Failed driver calls by storage, driver and method.",
+	}, []string{"storage", "driver", "method"})
+	connectionsInUse = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "max_connections_in_use",
+		Help:      "Number of requests currently holding a slot of the max connections limit.",
+	})
+	connectionsLimit = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "max_connections_limit",
+		Help:      "Configured max connections limit, 0 means unlimited.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		requestsTotal, requestDuration, proxyBytes, listCache,
+		driverCallDuration, driverCallErrors, connectionsInUse, connectionsLimit,
+	)
+}
+
+// ObserveRequest records a finished request, status is the http status code
+// for http based protocols and "ok" or "error" for the others
+func ObserveRequest(protocol, route, method, status string, start time.Time) {
+	requestsTotal.WithLabelValues(protocol, route, method, status).Inc()
+	requestDuration.WithLabelValues(protocol, route, method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveHTTPRequest is a shortcut of ObserveRequest for http status code
+func ObserveHTTPRequest(protocol, route, method string, code int, start time.Time) {
+	ObserveRequest(protocol, route, method, strconv.Itoa(code), start)
+}
+
+// ObserveOperation is a shortcut of ObserveRequest for protocols without status code
+func ObserveOperation(protocol, operation string, start time.Time, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	ObserveRequest(protocol, operation, operation, status, start)
+}
+
+func AddProxyBytes(n int) {
+	proxyBytes.Add(float64(n))
+}
+
+func ListCacheHit() {
+	listCache.WithLabelValues("hit").Inc()
+}
+
+func ListCacheMiss() {
+	listCache.WithLabelValues("miss").Inc()
+}
+
+func ObserveDriverCall(storage, driver, method string, start time.Time, err error) {
+	driverCallDuration.WithLabelValues(storage, driver, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		driverCallErrors.WithLabelValues(storage, driver, method).Inc()
+	}
+}
+
+func SetConnectionsLimit(n int) {
+	connectionsLimit.Set(float64(n))
+}
+
+func IncConnectionsInUse() {
+	connectionsInUse.Inc()
+}
+
+func DecConnectionsInUse() {
+	connectionsInUse.Dec()
+}
+
+// CountingWriter counts the bytes written to the underlying http.ResponseWriter
+type CountingWriter struct {
+	http.ResponseWriter
+}
+
+func (w CountingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	AddProxyBytes(n)
+	return n, err
+}
+
+func (w CountingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ReadFrom keeps the sendfile path of the underlying writer
+func (w CountingWriter) ReadFrom(r io.Reader) (int64, error) {
+	rf, ok := w.ResponseWriter.(io.ReaderFrom)
+	if !ok {
+		// Write counts the bytes
+		return io.Copy(writerOnly{w}, r)
+	}
+	n, err := rf.ReadFrom(r)
+	AddProxyBytes(int(n))
+	return n, err
+}
+
+func (w CountingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// writerOnly hides ReadFrom so io.Copy doesn't call it again
+type writerOnly struct {
+	io.Writer
+}
+
+func (w CountingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
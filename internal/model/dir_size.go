@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// DirSize is the recursive size of a dir computed by a du task
+type DirSize struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	Path      string    `json:"path" gorm:"index"`
+	Size      int64     `json:"size"`
+	Files     int64     `json:"files"`
+	Dirs      int64     `json:"dirs"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
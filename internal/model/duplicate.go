@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// DuplicateGroup is a set of files with the same content found by a duplicate scan
+type DuplicateGroup struct {
+	ID        uint            `json:"id" gorm:"primaryKey"`
+	Size      int64           `json:"size"`
+	Hash      string          `json:"hash"` // <hash type>:<hex>
+	CreatedAt time.Time       `json:"created_at"`
+	Files     []DuplicateFile `json:"files" gorm:"-"`
+}
+
+const (
+	DuplicateFileRemoved  = "removed"
+	DuplicateFileReplaced = "replaced"
+)
+
+type DuplicateFile struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	GroupID  uint      `json:"group_id" gorm:"index"`
+	Path     string    `json:"path" gorm:"type:text"`
+	Modified time.Time `json:"modified"`
+	Status   string    `json:"status"` // empty, removed or replaced
+}
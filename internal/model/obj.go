@@ -58,6 +58,11 @@ type Thumb interface {
 	Thumb() string
 }
 
+// CID is the content identifier of an obj stored on ipfs
+type CID interface {
+	CID() string
+}
+
 type SetPath interface {
 	SetPath(path string)
 }
@@ -148,6 +153,16 @@ func GetUrl(obj Obj) (url string, ok bool) {
 	return url, false
 }
 
+func GetCID(obj Obj) (cid string, ok bool) {
+	if obj, ok := obj.(CID); ok {
+		return obj.CID(), true
+	}
+	if unwrap, ok := obj.(ObjUnwrap); ok {
+		return GetCID(unwrap.Unwrap())
+	}
+	return cid, false
+}
+
 func GetRawObject(obj Obj) *Object {
 	switch v := obj.(type) {
 	case *ObjThumbURL:
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+type UploadLimit struct {
+	Quota       int64  `json:"quota"`         // max total bytes uploaded, 0 means unlimited
+	MaxFileSize int64  `json:"max_file_size"` // max bytes of a single file, 0 means unlimited
+	AllowedExts string `json:"allowed_exts"`  // comma separated, empty means all
+	BlockedExts string `json:"blocked_exts"`  // comma separated
+}
+
+func splitExts(exts string) []string {
+	var res []string
+	for _, ext := range strings.Split(exts, ",") {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext != "" {
+			res = append(res, ext)
+		}
+	}
+	return res
+}
+
+// CheckName checks the extension of the file name
+func (l *UploadLimit) CheckName(name string) error {
+	ext := utils.Ext(name)
+	if allowed := splitExts(l.AllowedExts); len(allowed) > 0 && !utils.SliceContains(allowed, ext) {
+		return errors.WithStack(errs.ExtNotAllowed)
+	}
+	if utils.SliceContains(splitExts(l.BlockedExts), ext) {
+		return errors.WithStack(errs.ExtNotAllowed)
+	}
+	return nil
+}
+
+// CheckSize checks the size of the file and the total bytes after upload, size < 0 means unknown
+func (l *UploadLimit) CheckSize(size, used int64) error {
+	if size < 0 {
+		return nil
+	}
+	if l.MaxFileSize > 0 && size > l.MaxFileSize {
+		return errors.WithStack(errs.FileTooLarge)
+	}
+	if l.Quota > 0 && used+size > l.Quota {
+		return errors.WithStack(errs.QuotaExceeded)
+	}
+	return nil
+}
+
+// UploadRecord is the audit of a finished upload, used to calculate the usage of quotas
+type UploadRecord struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserId    uint      `json:"user_id" gorm:"index"`
+	Path      string    `json:"path" gorm:"index"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
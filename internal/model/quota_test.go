@@ -0,0 +1,35 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+)
+
+func TestUploadLimit(t *testing.T) {
+	l := UploadLimit{
+		Quota:       100,
+		MaxFileSize: 50,
+		AllowedExts: "jpg, .PNG",
+		BlockedExts: "exe",
+	}
+	if err := l.CheckName("a.png"); err != nil {
+		t.Errorf("a.png should be allowed, got %v", err)
+	}
+	if err := l.CheckName("a.txt"); !errors.Is(err, errs.ExtNotAllowed) {
+		t.Errorf("a.txt should not be allowed, got %v", err)
+	}
+	if err := (&UploadLimit{BlockedExts: "exe"}).CheckName("a.EXE"); !errors.Is(err, errs.ExtNotAllowed) {
+		t.Errorf("a.EXE should be blocked, got %v", err)
+	}
+	if err := l.CheckSize(60, 0); !errors.Is(err, errs.FileTooLarge) {
+		t.Errorf("expect file too large, got %v", err)
+	}
+	if err := l.CheckSize(40, 70); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("expect quota exceeded, got %v", err)
+	}
+	if err := l.CheckSize(-1, 1000); err != nil {
+		t.Errorf("unknown size should pass, got %v", err)
+	}
+}
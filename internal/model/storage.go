@@ -19,6 +19,9 @@ type Storage struct {
 	EnableSign      bool      `json:"enable_sign"`
 	Sort
 	Proxy
+	Trash
+	Versioning
+	Balance
 }
 
 type Sort struct {
@@ -34,6 +37,33 @@ type Proxy struct {
 	DownProxyUrl string `json:"down_proxy_url"`
 }
 
+type Trash struct {
+	EnableTrash    bool   `json:"enable_trash"`
+	TrashPath      string `json:"trash_path"`      // mount path of the trash dir, empty means .alist-trash in the root of the storage
+	TrashRetention int    `json:"trash_retention"` // days to keep removed objects, 0 means forever
+}
+
+type Versioning struct {
+	EnableVersions   bool `json:"enable_versions"`
+	MaxVersions      int  `json:"max_versions"`      // versions to keep for each file, 0 means unlimited
+	VersionRetention int  `json:"version_retention"` // days to keep versions, 0 means forever
+}
+
+type Balance struct {
+	BalancePolicy string `json:"balance_policy"` // policy of the virtual path, only the one of the first storage sorted by mount path is used
+	BalanceWeight int    `json:"balance_weight"` // weight in weighted_round_robin, less than 1 means 1
+	EjectCooldown int    `json:"eject_cooldown"` // seconds to skip the storage after a failure, 0 means 60
+}
+
+type StorageDetails struct {
+	TotalSpace int64 `json:"total_space"`
+	FreeSpace  int64 `json:"free_space"`
+}
+
+func (d *StorageDetails) UsedSpace() int64 {
+	return d.TotalSpace - d.FreeSpace
+}
+
 func (s *Storage) GetStorage() *Storage {
 	return s
 }
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+type Subscription struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Name         string    `json:"name" binding:"required"`
+	URL          string    `json:"url" binding:"required"`
+	Include      string    `json:"include"` // regexp matched against the item title, empty means all
+	Exclude      string    `json:"exclude"` // regexp matched against the item title
+	DstPath      string    `json:"dst_path" binding:"required"`
+	Tool         string    `json:"tool" binding:"required"`
+	DeletePolicy string    `json:"delete_policy"`
+	Interval     int       `json:"interval"` // minutes
+	Disabled     bool      `json:"disabled"`
+	LastCheck    time.Time `json:"last_check"`
+	LastError    string    `json:"last_error"`
+}
+
+const (
+	SubscriptionItemAdded  = "added"
+	SubscriptionItemFailed = "failed"
+)
+
+// SubscriptionItem is a feed item that has been enqueued, the history of a subscription
+type SubscriptionItem struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint      `json:"subscription_id" gorm:"index"`
+	GUID           string    `json:"guid" gorm:"index"`
+	Title          string    `json:"title"`
+	URL            string    `json:"url" gorm:"type:text"`
+	Status         string    `json:"status"`
+	TaskID         string    `json:"task_id"`
+	Error          string    `json:"error" gorm:"type:text"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+const (
+	// SyncMirror makes the dst identical to the src, files only in the dst are deleted
+	SyncMirror = "mirror"
+	// SyncUpdate copies new and newer files of the src to the dst, nothing is deleted
+	SyncUpdate = "update"
+	// SyncTwoWay copies files missing on either side, the newer one wins on a conflict.
+	// It's copy-only, no state of the last run is kept, so a file deleted on one side is copied back from the other
+	SyncTwoWay = "two_way"
+
+	SyncCompareSizeMtime = "size_mtime"
+	SyncCompareHash      = "hash"
+)
+
+// SyncJob is a sync between two paths that runs periodically
+type SyncJob struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Name       string    `json:"name" binding:"required"`
+	SrcPath    string    `json:"src_path" binding:"required"`
+	DstPath    string    `json:"dst_path" binding:"required"`
+	Mode       string    `json:"mode"`
+	Compare    string    `json:"compare"`
+	Include    string    `json:"include"`  // glob patterns separated by newlines, empty means all
+	Exclude    string    `json:"exclude"`  // glob patterns separated by newlines
+	Interval   int       `json:"interval"` // minutes, 0 means run manually only
+	Disabled   bool      `json:"disabled"`
+	LastRun    time.Time `json:"last_run"`
+	LastTaskID string    `json:"last_task_id"`
+	LastError  string    `json:"last_error"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type TrashItem struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserId       uint      `json:"user_id" gorm:"index"`
+	Name         string    `json:"name"`
+	OriginalPath string    `json:"original_path"` // full path of the object before removed
+	TrashPath    string    `json:"trash_path"`    // full path of the object in the trash
+	Size         int64     `json:"size"`
+	IsDir        bool      `json:"is_dir"`
+	RemovedAt    time.Time `json:"removed_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// TusUpload is an unfinished tus upload, the received bytes are staged in TempFile
+type TusUpload struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	UserId    uint      `json:"user_id" gorm:"index"`
+	Path      string    `json:"path"` // full destination path
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`
+	Metadata  string    `json:"metadata"` // raw Upload-Metadata header
+	TempFile  string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
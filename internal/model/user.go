@@ -48,6 +48,7 @@ type User struct {
 	OtpSecret  string `json:"-"`
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
+	UploadLimit
 }
 
 func (u *User) IsGuest() bool {
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type FileVersion struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Path        string    `json:"path" gorm:"index"` // full path of the versioned file
+	VersionPath string    `json:"version_path"`      // full path of the old content in the versions dir
+	Size        int64     `json:"size"`
+	Modified    time.Time `json:"modified"`
+	CreatedAt   time.Time `json:"created_at"`
+}
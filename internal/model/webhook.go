@@ -0,0 +1,53 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+type Webhook struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Name     string `json:"name" binding:"required"`
+	URL      string `json:"url" binding:"required"`
+	Secret   string `json:"secret"`
+	Events   string `json:"events"` // comma separated event names, empty means all events
+	MaxRetry int    `json:"max_retry"`
+	Timeout  int    `json:"timeout"` // seconds
+	Disabled bool   `json:"disabled"`
+}
+
+// Subscribed reports whether the webhook should receive the event
+func (w *Webhook) Subscribed(event string) bool {
+	if w.Disabled {
+		return false
+	}
+	if strings.TrimSpace(w.Events) == "" {
+		return true
+	}
+	for _, e := range strings.Split(w.Events, ",") {
+		e = strings.TrimSpace(e)
+		if e == "*" || e == event {
+			return true
+		}
+		// support prefix match such as `file.*`
+		if strings.HasSuffix(e, ".*") && strings.HasPrefix(event, strings.TrimSuffix(e, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WebhookID  uint      `json:"webhook_id" gorm:"index"`
+	EventID    string    `json:"event_id"`
+	Event      string    `json:"event"`
+	Payload    string    `json:"payload" gorm:"type:text"`
+	StatusCode int       `json:"status_code"`
+	Response   string    `json:"response" gorm:"type:text"`
+	Error      string    `json:"error" gorm:"type:text"`
+	Attempts   int       `json:"attempts"`
+	Success    bool      `json:"success"`
+	Duration   int64     `json:"duration"` // milliseconds
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,274 @@
+//go:build !cgo || nosqlite
+
+package bittorrent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/anacrolix/torrent/storage"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type BitTorrent struct {
+	client   *torrent.Client
+	torrents generic_sync.MapOf[string, *item]
+}
+
+// item is a torrent added by a task
+type item struct {
+	t *torrent.Torrent
+	// spec is kept to add the torrent again when the client is reinitialized
+	spec     *torrent.TorrentSpec
+	tempDir  string
+	patterns []string
+	// selected is set once the files to download are marked
+	selected atomic.Bool
+	// completed is the time all selected files finished, zero if not yet
+	completed time.Time
+}
+
+func (b *BitTorrent) Name() string {
+	return "BitTorrent"
+}
+
+func (b *BitTorrent) Items() []model.SettingItem {
+	// bittorrent settings
+	return []model.SettingItem{
+		{Key: conf.BitTorrentListenPort, Value: "42069", Type: conf.TypeNumber, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.BitTorrentSeedRatio, Value: "0", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.BitTorrentSeedtime, Value: "0", Type: conf.TypeNumber, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+	}
+}
+
+func (b *BitTorrent) Init() (string, error) {
+	// the torrents of the running tasks are moved to the new client with the same gid
+	var items []*item
+	if b.client != nil {
+		b.torrents.Range(func(gid string, it *item) bool {
+			items = append(items, it)
+			b.torrents.Delete(gid)
+			return true
+		})
+		b.client.Close()
+		b.client = nil
+	}
+	cfg := torrent.NewDefaultClientConfig()
+	cfg.DataDir = filepath.Join(conf.Conf.TempDir, "BitTorrent")
+	cfg.DefaultStorage = newStorage(cfg.DataDir)
+	cfg.ListenPort = setting.GetInt(conf.BitTorrentListenPort, 42069)
+	cfg.Seed = true
+	client, err := torrent.NewClient(cfg)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to init bittorrent client")
+	}
+	b.client = client
+	for _, it := range items {
+		if _, err = b.add(&item{spec: it.spec, tempDir: it.tempDir, patterns: it.patterns, completed: it.completed}); err != nil {
+			log.Errorf("[bittorrent] failed to add %s again: %+v", it.spec.InfoHash.HexString(), err)
+		}
+	}
+	return fmt.Sprintf("bittorrent client listening on %d", client.LocalPort()), nil
+}
+
+func (b *BitTorrent) IsReady() bool {
+	return b.client != nil
+}
+
+func (b *BitTorrent) AddURL(args *tool.AddUrlArgs) (string, error) {
+	spec, err := getSpec(args.Ctx, args.Url)
+	if err != nil {
+		return "", err
+	}
+	return b.add(&item{spec: spec, tempDir: args.TempDir, patterns: args.Files})
+}
+
+// add adds the torrent of it.spec to the client, the files are marked once the info is got
+func (b *BitTorrent) add(it *item) (string, error) {
+	it.spec.Storage = newStorage(it.tempDir)
+	t, _, err := b.client.AddTorrentSpec(it.spec)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to add torrent")
+	}
+	it.t = t
+	gid := t.InfoHash().HexString()
+	b.torrents.Store(gid, it)
+	go func() {
+		select {
+		case <-t.GotInfo():
+			if err := selectFiles(t, it.patterns); err != nil {
+				log.Errorf("[bittorrent] %s: %+v", gid, err)
+			}
+			it.selected.Store(true)
+		case <-t.Closed():
+		}
+	}()
+	return gid, nil
+}
+
+func (b *BitTorrent) Remove(task *tool.DownloadTask) error {
+	it, ok := b.torrents.Load(task.GID)
+	if !ok {
+		return nil
+	}
+	b.torrents.Delete(task.GID)
+	it.t.Drop()
+	return nil
+}
+
+func (b *BitTorrent) Status(task *tool.DownloadTask) (*tool.Status, error) {
+	it, ok := b.torrents.Load(task.GID)
+	if !ok {
+		return nil, fmt.Errorf("failed get status, wrong gid: %s", task.GID)
+	}
+	t := it.t
+	s := &tool.Status{}
+	if !it.selected.Load() {
+		s.Status = "[bittorrent] fetching metadata"
+		return s, nil
+	}
+	var done int64
+	for _, f := range t.Files() {
+		if f.Priority() == torrent.PiecePriorityNone {
+			continue
+		}
+		s.TotalBytes += f.Length()
+		done += f.BytesCompleted()
+	}
+	if s.TotalBytes == 0 {
+		s.Err = errors.Errorf("[bittorrent] no file of %s matches %s", t.Name(), strings.Join(it.patterns, ","))
+		return s, nil
+	}
+	s.Progress = float64(done) / float64(s.TotalBytes) * 100
+	stats := t.Stats()
+	s.Status = fmt.Sprintf("[bittorrent] downloading %s, %d/%d peers", t.Name(), stats.ActivePeers, stats.TotalPeers)
+	if done == s.TotalBytes {
+		if it.completed.IsZero() {
+			it.completed = time.Now()
+			removeUnselected(t, it.tempDir)
+		}
+		s.Completed = true
+	}
+	return s, nil
+}
+
+// Seed keeps seeding until the seed ratio or seed time is reached,
+// a negative seed time means seeding until the task is canceled
+func (b *BitTorrent) Seed(task *tool.DownloadTask) error {
+	it, ok := b.torrents.Load(task.GID)
+	if !ok {
+		return nil
+	}
+	seedtime := time.Duration(setting.GetInt(conf.BitTorrentSeedtime, 0)) * time.Minute
+	var ratio float64
+	if _, err := fmt.Sscan(setting.GetStr(conf.BitTorrentSeedRatio, "0"), &ratio); err != nil {
+		ratio = 0
+	}
+	if seedtime == 0 && ratio <= 0 {
+		return nil
+	}
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		stats := it.t.Stats()
+		if ratio > 0 && it.t.Length() > 0 && float64(stats.BytesWrittenData.Int64())/float64(it.t.Length()) >= ratio {
+			return nil
+		}
+		if seedtime > 0 && time.Since(it.completed) >= seedtime {
+			return nil
+		}
+		select {
+		case <-task.CtxDone():
+			return nil
+		case <-it.t.Closed():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *BitTorrent) Run(task *tool.DownloadTask) error {
+	return errs.NotSupport
+}
+
+// getSpec accepts a magnet uri, an info hash or a http(s) url of a .torrent file
+func getSpec(ctx context.Context, uri string) (*torrent.TorrentSpec, error) {
+	if strings.HasPrefix(uri, "magnet:") {
+		spec, err := torrent.TorrentSpecFromMagnetUri(uri)
+		return spec, errors.Wrap(err, "invalid magnet uri")
+	}
+	if len(uri) == 40 {
+		var ih metainfo.Hash
+		if err := ih.FromHexString(uri); err == nil {
+			return &torrent.TorrentSpec{InfoHash: ih}, nil
+		}
+	}
+	u, err := url.Parse(uri)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.Errorf("unsupported torrent uri: %s", uri)
+	}
+	res, err := base.RestyClient.R().SetContext(ctx).Get(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get .torrent file")
+	}
+	if res.StatusCode() >= 400 {
+		return nil, errors.Errorf("failed to get .torrent file, http status code %d", res.StatusCode())
+	}
+	mi, err := metainfo.Load(bytes.NewReader(res.Body()))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid .torrent file")
+	}
+	return torrent.TorrentSpecFromMetaInfoErr(mi)
+}
+
+func newStorage(dir string) storage.ClientImpl {
+	return storage.NewFileOpts(storage.NewFileClientOpts{
+		ClientBaseDir:   dir,
+		PieceCompletion: storage.NewMapPieceCompletion(),
+	})
+}
+
+// removeUnselected deletes the files that were only partly written
+// because they share pieces with the selected ones
+func removeUnselected(t *torrent.Torrent, tempDir string) {
+	for _, f := range t.Files() {
+		if f.Priority() != torrent.PiecePriorityNone {
+			continue
+		}
+		name := filepath.Join(tempDir, filepath.FromSlash(f.Path()))
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			log.Warnf("[bittorrent] failed to remove unselected file %s: %+v", name, err)
+			continue
+		}
+		// remove the dirs left empty, os.Remove fails on the others
+		for dir := filepath.Dir(name); dir != tempDir && strings.HasPrefix(dir, tempDir); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+}
+
+var _ tool.Tool = (*BitTorrent)(nil)
+var _ tool.Seeder = (*BitTorrent)(nil)
+
+func init() {
+	tool.Tools.Add(&BitTorrent{})
+}
@@ -0,0 +1,53 @@
+//go:build cgo && !nosqlite
+
+package bittorrent
+
+import (
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/pkg/errors"
+)
+
+// The torrent engine links its own sqlite with cgo, which conflicts with the one of the
+// database driver at link time. So a cgo build only includes the engine with the nosqlite
+// tag, as build.sh does, otherwise the tool is listed but fails to init with errUnavailable.
+var errUnavailable = errors.New("the BitTorrent tool is not in this build, build with -tags nosqlite or CGO_ENABLED=0 to include it")
+
+type BitTorrent struct{}
+
+func (b *BitTorrent) Name() string {
+	return "BitTorrent"
+}
+
+func (b *BitTorrent) Items() []model.SettingItem {
+	return nil
+}
+
+func (b *BitTorrent) Init() (string, error) {
+	return "", errUnavailable
+}
+
+func (b *BitTorrent) IsReady() bool {
+	return false
+}
+
+func (b *BitTorrent) AddURL(args *tool.AddUrlArgs) (string, error) {
+	return "", errUnavailable
+}
+
+func (b *BitTorrent) Remove(task *tool.DownloadTask) error {
+	return nil
+}
+
+func (b *BitTorrent) Status(task *tool.DownloadTask) (*tool.Status, error) {
+	return nil, errUnavailable
+}
+
+func (b *BitTorrent) Run(task *tool.DownloadTask) error {
+	return errs.NotSupport
+}
+
+func init() {
+	tool.Tools.Add(&BitTorrent{})
+}
@@ -0,0 +1,43 @@
+//go:build !cgo || nosqlite
+
+package bittorrent
+
+import (
+	"path"
+	"strings"
+
+	"github.com/anacrolix/torrent"
+	"github.com/pkg/errors"
+)
+
+// selectFiles marks the files matching the patterns for download, all files
+// are downloaded if no pattern is given. A pattern matches a file by its path
+// within the torrent, a parent dir of it, or as a glob of path.Match
+func selectFiles(t *torrent.Torrent, patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return errors.Wrapf(err, "invalid file pattern %s", p)
+		}
+	}
+	for _, f := range t.Files() {
+		if len(patterns) == 0 || matchFile(f.DisplayPath(), patterns) {
+			f.Download()
+		} else {
+			f.SetPriority(torrent.PiecePriorityNone)
+		}
+	}
+	return nil
+}
+
+func matchFile(name string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.Trim(p, "/")
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+//go:build !cgo || nosqlite
+
+package bittorrent
+
+import "testing"
+
+func TestMatchFile(t *testing.T) {
+	patterns := []string{"video/*.mkv", "/subs/", "readme.txt"}
+	tests := map[string]bool{
+		"video/a.mkv":     true,
+		"video/a.mp4":     false,
+		"video/sub/a.mkv": false,
+		"subs/en/a.srt":   true,
+		"subsidy.txt":     false,
+		"readme.txt":      true,
+		"docs/readme.txt": false,
+	}
+	for name, want := range tests {
+		if got := matchFile(name, patterns); got != want {
+			t.Errorf("matchFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
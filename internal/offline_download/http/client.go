@@ -2,16 +2,25 @@ package http
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/net"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	log "github.com/sirupsen/logrus"
 )
 
 type SimpleHttp struct {
@@ -23,7 +32,10 @@ func (s SimpleHttp) Name() string {
 }
 
 func (s SimpleHttp) Items() []model.SettingItem {
-	return nil
+	return []model.SettingItem{
+		{Key: conf.SimpleHttpConcurrency, Value: "4", Type: conf.TypeNumber, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.SimpleHttpStream, Value: "false", Type: conf.TypeBool, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+	}
 }
 
 func (s SimpleHttp) Init() (string, error) {
@@ -53,10 +65,15 @@ func (s SimpleHttp) Run(task *tool.DownloadTask) error {
 	if err != nil {
 		return err
 	}
+	header := http.Header{}
+	for k, v := range task.Headers {
+		header.Set(k, v)
+	}
 	req, err := http.NewRequestWithContext(task.Ctx(), http.MethodGet, u, nil)
 	if err != nil {
 		return err
 	}
+	req.Header = header.Clone()
 	resp, err := s.client.Do(req)
 	if err != nil {
 		return err
@@ -74,18 +91,130 @@ func (s SimpleHttp) Run(task *tool.DownloadTask) error {
 	if n, err := parseFilenameFromContentDisposition(resp.Header.Get("Content-Disposition")); err == nil {
 		filename = n
 	}
+	if task.Filename != "" {
+		filename = task.Filename
+	}
+	fileSize := resp.ContentLength
+	task.SetTotalBytes(fileSize)
+	if fileSize > 0 && setting.GetBool(conf.SimpleHttpStream) && task.CanPutStream() {
+		return s.putStream(task, resp, filename)
+	}
 	// save to temp dir
 	_ = os.MkdirAll(task.TempDir, os.ModePerm)
 	filePath := filepath.Join(task.TempDir, filename)
+	if fileSize > 0 && resp.Header.Get("Accept-Ranges") == "bytes" {
+		_ = resp.Body.Close()
+		err = s.download(task, header, filePath, fileSize, resumeValidator(resp.Header))
+	} else {
+		err = s.save(task, resp.Body, filePath, fileSize)
+	}
+	if err != nil {
+		return err
+	}
+	return verifyChecksum(filePath, task.Checksum)
+}
+
+// putStream pipes the body into the dst storage without touching the temp dir
+func (s SimpleHttp) putStream(task *tool.DownloadTask, resp *http.Response, filename string) error {
+	var reader io.Reader = resp.Body
+	if task.Checksum != "" {
+		ht, expected, err := common.ParseFileHash(task.Checksum)
+		if err != nil {
+			return err
+		}
+		reader = stream.NewVerifyReader(reader, ht, expected, resp.ContentLength)
+	}
+	modified := time.Now()
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		modified = t
+	}
+	mimetype := resp.Header.Get("Content-Type")
+	if mimetype == "" {
+		mimetype = utils.GetMimeType(filename)
+	}
+	return task.PutStream(&stream.FileStream{
+		Ctx: task.Ctx(),
+		Obj: &model.Object{
+			Name:     filename,
+			Size:     resp.ContentLength,
+			Modified: modified,
+		},
+		Reader:   reader,
+		Mimetype: mimetype,
+		Closers:  utils.NewClosers(resp.Body),
+	})
+}
+
+// save writes the whole body to filePath, used when the server doesn't support ranges
+func (s SimpleHttp) save(task *tool.DownloadTask, body io.Reader, filePath string, fileSize int64) error {
 	file, err := os.Create(filePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	fileSize := resp.ContentLength
-	task.SetTotalBytes(fileSize)
-	err = utils.CopyWithCtx(task.Ctx(), file, resp.Body, fileSize, task.SetProgress)
-	return err
+	return utils.CopyWithCtx(task.Ctx(), file, body, fileSize, task.SetProgress)
+}
+
+// resumeValidator returns the strong ETag, or else the Last-Modified, telling whether the file is still the same
+func resumeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// download fetches the file with ranged requests over multiple connections,
+// continuing from the partial file left in filePath by a previous run if the validator of the file is unchanged
+func (s SimpleHttp) download(task *tool.DownloadTask, header http.Header, filePath string, fileSize int64, validator string) error {
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	offset := info.Size()
+	if offset > fileSize || validator == "" || validator != task.Validator {
+		// not the same file anymore, or no way to know it
+		offset = 0
+	}
+	task.Validator = validator
+	if validator != "" {
+		// the server sends the whole file instead of a range if it changes meanwhile
+		header = header.Clone()
+		header.Set("If-Range", validator)
+	}
+	if err = file.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if offset == fileSize {
+		task.SetProgress(100)
+		return nil
+	}
+	if offset > 0 {
+		log.Infof("[SimpleHttp] resume %s from %d/%d", task.Url, offset, fileSize)
+	}
+	remain := fileSize - offset
+	rc, err := net.NewDownloader(func(d *net.Downloader) {
+		d.Concurrency = setting.GetInt(conf.SimpleHttpConcurrency, net.DefaultDownloadConcurrency)
+	}).Download(task.Ctx(), &net.HttpRequestParams{
+		URL:       task.Url,
+		Range:     http_range.Range{Start: offset, Length: remain},
+		HeaderRef: header,
+		Size:      fileSize,
+	})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return utils.CopyWithCtx(task.Ctx(), file, rc, remain, func(p float64) {
+		task.SetProgress((float64(offset) + p*float64(remain)/100) * 100 / float64(fileSize))
+	})
 }
 
 func init() {
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/testutil"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	testutil.InitDB()
+}
+
+func TestRunResume(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<16)
+	var (
+		mu      sync.Mutex
+		cookies []string
+		ranges  []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	sum := md5.Sum(data)
+	task := &tool.DownloadTask{
+		Url:       srv.URL + "/file.bin",
+		TempDir:   t.TempDir(),
+		Headers:   map[string]string{"Cookie": "a=b"},
+		Checksum:  "md5=" + hex.EncodeToString(sum[:]),
+		Validator: `"v1"`,
+	}
+	task.SetCtx(context.Background())
+	filePath := filepath.Join(task.TempDir, "file.bin")
+	// a partial file left by a previous run
+	if err := os.WriteFile(filePath, data[:1000], 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := (SimpleHttp{}).Run(task); err != nil {
+		t.Fatalf("run: %+v", err)
+	}
+	got, _ := os.ReadFile(filePath)
+	if !bytes.Equal(got, data) {
+		t.Fatalf("downloaded %d bytes, want %d", len(got), len(data))
+	}
+	for _, c := range cookies {
+		if c != "a=b" {
+			t.Fatalf("header not sent: %q", c)
+		}
+	}
+	for _, r := range ranges[1:] {
+		if !strings.HasPrefix(r, "bytes=") || strings.HasPrefix(r, "bytes=0-") {
+			t.Fatalf("not resumed, range: %q", r)
+		}
+	}
+	if b, _ := json.Marshal(task); bytes.Contains(b, []byte("a=b")) {
+		t.Fatalf("headers persisted: %s", b)
+	}
+
+	// the partial file of another version of the file is downloaded again
+	task.Validator = `"v0"`
+	if err := os.WriteFile(filePath, bytes.Repeat([]byte("x"), 1000), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := (SimpleHttp{}).Run(task); err != nil {
+		t.Fatalf("run: %+v", err)
+	}
+	if task.Validator != `"v1"` {
+		t.Fatalf("validator not updated: %s", task.Validator)
+	}
+
+	task.Checksum = "md5=" + hex.EncodeToString(make([]byte, md5.Size))
+	if err := (SimpleHttp{}).Run(task); !errors.Is(err, errs.ChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestRunStream(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	_, err := op.CreateStorage(context.Background(), model.Storage{
+		MountPath: "/stream",
+		Driver:    "Local",
+		Addition:  `{"root_folder_path":"` + root + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("create storage: %+v", err)
+	}
+	if err = op.SaveSettingItem(&model.SettingItem{Key: conf.SimpleHttpStream, Value: "true", Type: conf.TypeBool}); err != nil {
+		t.Fatal(err)
+	}
+	defer op.DeleteSettingItemByKey(conf.SimpleHttpStream)
+
+	task := &tool.DownloadTask{
+		Url:        srv.URL + "/file.bin",
+		DstDirPath: "/stream",
+		TempDir:    t.TempDir(),
+	}
+	task.SetCtx(context.Background())
+	if err = (SimpleHttp{}).Run(task); err != nil {
+		t.Fatalf("run: %+v", err)
+	}
+	if entries, _ := os.ReadDir(task.TempDir); len(entries) != 0 {
+		t.Fatalf("temp dir should be empty, got %d entries", len(entries))
+	}
+	got, _ := os.ReadFile(filepath.Join(root, "file.bin"))
+	if !bytes.Equal(got, data) {
+		t.Fatalf("uploaded %d bytes, want %d", len(got), len(data))
+	}
+}
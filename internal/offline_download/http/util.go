@@ -3,6 +3,13 @@ package http
 import (
 	"fmt"
 	"mime"
+	"os"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
 )
 
 func parseFilenameFromContentDisposition(contentDisposition string) (string, error) {
@@ -19,3 +26,29 @@ func parseFilenameFromContentDisposition(contentDisposition string) (string, err
 	}
 	return filename, nil
 }
+
+// verifyChecksum checks the downloaded file against the expected checksum,
+// the file is removed on mismatch so that a retry starts over
+func verifyChecksum(filePath, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	ht, expected, err := common.ParseFileHash(checksum)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	sum, err := utils.HashFile(ht, file)
+	_ = file.Close()
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(sum, expected) {
+		_ = os.Remove(filePath)
+		return errors.Wrapf(errs.ChecksumMismatch, "expected %s, got %s", expected, sum)
+	}
+	return nil
+}
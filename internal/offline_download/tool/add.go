@@ -16,6 +16,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
@@ -34,6 +35,14 @@ type AddURLArgs struct {
 	DstDirPath   string
 	Tool         string
 	DeletePolicy DeletePolicy
+	// Headers are sent with every request made for the url, only used by SimpleHttp
+	Headers map[string]string
+	// Checksum is the expected hash of the downloaded file, e.g. "md5=<hex>"
+	Checksum string
+	// Files to download within a torrent, only used by BitTorrent
+	Files []string
+	// Filename overrides the name of the downloaded file, the cloud tools don't support it
+	Filename string
 }
 
 func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, error) {
@@ -58,8 +67,20 @@ func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, erro
 			return nil, errors.WithStack(errs.NotFolder)
 		}
 	}
-	// try putting url
-	if args.Tool == "SimpleHttp" && tryPutUrl(ctx, storage, dstDirActualPath, args.URL) {
+	if args.Filename != "" {
+		switch args.Tool {
+		case "115 Cloud", "PikPak", "Thunder":
+			// the files are moved within the cloud, there is no transfer to rename them
+			return nil, errors.Errorf("%s can't name the downloaded file", args.Tool)
+		}
+	}
+	if args.Checksum != "" {
+		if _, _, err := common.ParseFileHash(args.Checksum); err != nil {
+			return nil, errors.WithMessage(err, "invalid checksum")
+		}
+	}
+	// try putting url, the storage can't send the headers or verify the checksum
+	if args.Tool == "SimpleHttp" && len(args.Headers) == 0 && args.Checksum == "" && tryPutUrl(ctx, storage, dstDirActualPath, args.URL, args.Filename) {
 		return nil, nil
 	}
 
@@ -112,13 +133,17 @@ func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskExtensionInfo, erro
 		TempDir:      tempDir,
 		DeletePolicy: deletePolicy,
 		Toolname:     args.Tool,
+		Headers:      args.Headers,
+		Checksum:     args.Checksum,
+		Files:        args.Files,
+		Filename:     args.Filename,
 		tool:         tool,
 	}
 	DownloadTaskManager.Add(t)
 	return t, nil
 }
 
-func tryPutUrl(ctx context.Context, storage driver.Driver, dstDirActualPath, urlStr string) bool {
+func tryPutUrl(ctx context.Context, storage driver.Driver, dstDirActualPath, urlStr, filename string) bool {
 	_, ok := storage.(driver.PutURL)
 	_, okResult := storage.(driver.PutURLResult)
 	if !ok && !okResult {
@@ -129,6 +154,9 @@ func tryPutUrl(ctx context.Context, storage driver.Driver, dstDirActualPath, url
 		return false
 	}
 	dstName := path.Base(u.Path)
+	if filename != "" {
+		dstName = filename
+	}
 	err = op.PutURL(ctx, storage, dstDirActualPath, dstName, urlStr)
 	return err == nil
 }
@@ -1,14 +1,20 @@
 package tool
 
 import (
+	"context"
+
 	"github.com/alist-org/alist/v3/internal/model"
 )
 
 type AddUrlArgs struct {
+	// Ctx is canceled with the task
+	Ctx     context.Context
 	Url     string
 	UID     string
 	TempDir string
 	Signal  chan int
+	// Files to download within a torrent, empty means all
+	Files []string
 }
 
 type Status struct {
@@ -36,3 +42,9 @@ type Tool interface {
 	// Run for simple http download
 	Run(task *DownloadTask) error
 }
+
+// Seeder is implemented by tools that keep seeding after the download completed
+type Seeder interface {
+	// Seed blocks until the seeding limits are reached or the task is canceled
+	Seed(task *DownloadTask) error
+}
@@ -6,8 +6,12 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/internal/webhook"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/xhofe/tache"
@@ -15,16 +19,22 @@ import (
 
 type DownloadTask struct {
 	task.TaskExtension
-	Url               string       `json:"url"`
-	DstDirPath        string       `json:"dst_dir_path"`
-	TempDir           string       `json:"temp_dir"`
-	DeletePolicy      DeletePolicy `json:"delete_policy"`
-	Toolname          string       `json:"toolname"`
-	Status            string       `json:"-"`
-	Signal            chan int     `json:"-"`
-	GID               string       `json:"-"`
+	Url               string            `json:"url"`
+	DstDirPath        string            `json:"dst_dir_path"`
+	TempDir           string            `json:"temp_dir"`
+	DeletePolicy      DeletePolicy      `json:"delete_policy"`
+	Toolname          string            `json:"toolname"`
+	Headers           map[string]string `json:"-"`                   // may hold credentials, a restored task runs without them
+	Validator         string            `json:"validator,omitempty"` // ETag or Last-Modified of the partial file in TempDir
+	Checksum          string            `json:"checksum,omitempty"`
+	Files             []string          `json:"files,omitempty"`
+	Filename          string            `json:"filename,omitempty"`
+	Status            string            `json:"-"`
+	Signal            chan int          `json:"-"`
+	GID               string            `json:"-"`
 	tool              Tool
 	callStatusRetried int
+	streamed          bool
 }
 
 func (t *DownloadTask) Run() error {
@@ -49,10 +59,12 @@ func (t *DownloadTask) Run() error {
 		t.Signal = nil
 	}()
 	gid, err := t.tool.AddURL(&AddUrlArgs{
+		Ctx:     t.Ctx(),
 		Url:     t.Url,
 		UID:     t.ID,
 		TempDir: t.TempDir,
 		Signal:  t.Signal,
+		Files:   t.Files,
 	})
 	if err != nil {
 		return err
@@ -109,6 +121,18 @@ outer:
 		}
 	}
 
+	if seeder, ok := t.tool.(Seeder); ok {
+		t.Status = "offline download completed, seeding"
+		if err := seeder.Seed(t); err != nil {
+			log.Errorln(err.Error())
+		}
+		if err := t.tool.Remove(t); err != nil {
+			log.Errorln(err.Error())
+		}
+		t.Status = "offline download completed, transferring"
+		return errors.WithMessage(t.Transfer(), "failed to transfer file")
+	}
+
 	if t.tool.Name() == "Transmission" {
 		// hack for transmission
 		seedTime := setting.GetInt(conf.TransmissionSeedtime, 0)
@@ -146,6 +170,10 @@ func (t *DownloadTask) Update() (bool, error) {
 	}
 	// if download completed
 	if info.Completed {
+		if _, ok := t.tool.(Seeder); ok {
+			// transferred once seeded, the delete policy may remove the files being seeded
+			return true, nil
+		}
 		err := t.Transfer()
 		return true, errors.WithMessage(err, "failed to transfer file")
 	}
@@ -156,7 +184,32 @@ func (t *DownloadTask) Update() (bool, error) {
 	return false, nil
 }
 
+// CanPutStream reports whether the download can be uploaded to the dst storage
+// while downloading, instead of being staged in the temp dir first
+func (t *DownloadTask) CanPutStream() bool {
+	storage, _, err := op.GetStorageAndActualPath(t.DstDirPath)
+	if err != nil {
+		return false
+	}
+	return !storage.Config().NoUpload && !storage.Config().NeedStore
+}
+
+// PutStream uploads the file being downloaded to the dst storage,
+// the transfer step is skipped once it succeeds
+func (t *DownloadTask) PutStream(file model.FileStreamer) error {
+	t.Status = "streaming to the destination"
+	// through fs, so the limits and versions of the dst apply as uploading
+	if err := fs.PutDirectlyWithProgress(t.Ctx(), t.DstDirPath, file, t.SetProgress); err != nil {
+		return err
+	}
+	t.streamed = true
+	return nil
+}
+
 func (t *DownloadTask) Transfer() error {
+	if t.streamed {
+		return nil
+	}
 	toolName := t.tool.Name()
 	if toolName == "115 Cloud" || toolName == "PikPak" || toolName == "Thunder" {
 		// 如果不是直接下载到目标路径，则进行转存
@@ -165,7 +218,7 @@ func (t *DownloadTask) Transfer() error {
 		}
 		return nil
 	}
-	return transferStd(t.Ctx(), t.TempDir, t.DstDirPath, t.DeletePolicy)
+	return transferStd(t.Ctx(), t.TempDir, t.DstDirPath, t.DeletePolicy, t.Filename)
 }
 
 func (t *DownloadTask) GetName() string {
@@ -176,4 +229,12 @@ func (t *DownloadTask) GetStatus() string {
 	return t.Status
 }
 
+func (t *DownloadTask) OnSucceeded() {
+	webhook.EmitTask("offline_download", t)
+}
+
+func (t *DownloadTask) OnFailed() {
+	webhook.EmitTask("offline_download", t)
+}
+
 var DownloadTaskManager *tache.Manager[*DownloadTask]
@@ -0,0 +1,269 @@
+package tool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	ImportAuto     = ""
+	ImportMetalink = "metalink"
+	ImportAria2    = "aria2"
+	ImportCSV      = "csv"
+	ImportTSV      = "tsv"
+)
+
+// ImportEntry is a download parsed from an import file
+type ImportEntry struct {
+	// Line is the line number of the entry, or its index in a metalink
+	Line     int               `json:"line"`
+	URL      string            `json:"url"`
+	Dir      string            `json:"dir,omitempty"` // relative to the import destination
+	Name     string            `json:"name,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Checksum string            `json:"checksum,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// ParseImport parses a metalink, an aria2 input file, or csv/tsv rows of `url,dir,name`.
+// Invalid entries are returned with Error set, an error is returned only if the input can't be parsed at all
+func ParseImport(format string, r io.Reader) ([]ImportEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if format == ImportAuto {
+		format = detectImportFormat(data)
+	}
+	var entries []ImportEntry
+	switch format {
+	case ImportMetalink:
+		entries, err = parseMetalink(data)
+	case ImportAria2:
+		entries, err = parseAria2(data)
+	case ImportCSV:
+		entries, err = parseCSV(data, ',')
+	case ImportTSV:
+		entries, err = parseCSV(data, '\t')
+	default:
+		return nil, errors.Errorf("unsupported import format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].Error == "" {
+			entries[i].validate()
+		}
+	}
+	return entries, nil
+}
+
+func detectImportFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return ImportMetalink
+	}
+	line, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	switch {
+	case bytes.Contains(line, []byte("\t")):
+		return ImportTSV
+	case bytes.Contains(line, []byte(",")) && !bytes.HasPrefix(line, []byte("magnet:")):
+		return ImportCSV
+	}
+	return ImportAria2
+}
+
+func (e *ImportEntry) validate() {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		e.Error = "invalid url: " + err.Error()
+		return
+	}
+	switch u.Scheme {
+	case "http", "https", "ftp", "magnet":
+	default:
+		e.Error = "unsupported url scheme: " + u.Scheme
+		return
+	}
+	if e.Dir != "" {
+		dir := path.Clean(strings.Trim(e.Dir, "/"))
+		if dir == ".." || strings.HasPrefix(dir, "../") {
+			e.Error = "dir must not be outside the destination: " + e.Dir
+			return
+		}
+		if dir == "." {
+			dir = ""
+		}
+		e.Dir = dir
+	}
+	if e.Name != "" && (strings.ContainsAny(e.Name, `/\`) || e.Name == "." || e.Name == "..") {
+		e.Error = "invalid name: " + e.Name
+		return
+	}
+	if e.Checksum != "" {
+		if _, _, err := common.ParseFileHash(e.Checksum); err != nil {
+			e.Error = "invalid checksum: " + err.Error()
+		}
+	}
+}
+
+type metalinkHash struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type metalinkFile struct {
+	Name string `xml:"name,attr"`
+	// metalink 4
+	URLs   []string       `xml:"url"`
+	Hashes []metalinkHash `xml:"hash"`
+	// metalink 3
+	Resources    []string       `xml:"resources>url"`
+	Verification []metalinkHash `xml:"verification>hash"`
+}
+
+func parseMetalink(data []byte) ([]ImportEntry, error) {
+	var ml struct {
+		Files   []metalinkFile `xml:"file"`
+		V3Files []metalinkFile `xml:"files>file"`
+	}
+	if err := xml.Unmarshal(data, &ml); err != nil {
+		return nil, errors.Wrap(err, "invalid metalink")
+	}
+	var entries []ImportEntry
+	for i, f := range append(ml.Files, ml.V3Files...) {
+		e := ImportEntry{Line: i + 1}
+		urls := append(f.URLs, f.Resources...)
+		if len(urls) == 0 {
+			e.Error = "no url of file " + f.Name
+		} else {
+			e.URL = strings.TrimSpace(urls[0])
+		}
+		if name := strings.Trim(f.Name, "/"); name != "" {
+			e.Name = path.Base(name)
+			if dir := path.Dir(name); dir != "." {
+				e.Dir = dir
+			}
+		}
+		for _, h := range append(f.Hashes, f.Verification...) {
+			if h.Type == "md5" || h.Type == "sha-1" || h.Type == "sha-256" {
+				e.Checksum = h.Type + "=" + strings.TrimSpace(h.Value)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// parseAria2 parses an aria2 input file, each uri line may be followed by
+// indented options, dir, out, header, referer, user-agent and checksum are supported
+func parseAria2(data []byte) ([]ImportEntry, error) {
+	var entries []ImportEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if text[0] != ' ' && text[0] != '\t' {
+			// tab separated uris are mirrors of the same file
+			uri, _, _ := strings.Cut(trimmed, "\t")
+			entries = append(entries, ImportEntry{Line: line, URL: uri})
+			continue
+		}
+		if len(entries) == 0 {
+			return nil, errors.Errorf("line %d: option without uri", line)
+		}
+		e := &entries[len(entries)-1]
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			e.Error = "invalid option: " + trimmed
+			continue
+		}
+		switch key {
+		case "dir":
+			e.Dir = value
+		case "out":
+			e.Name = path.Base(value)
+			if dir := path.Dir(value); dir != "." {
+				e.Dir = path.Join(e.Dir, dir)
+			}
+		case "header":
+			name, v, ok := strings.Cut(value, ":")
+			if !ok {
+				e.Error = "invalid header: " + value
+				continue
+			}
+			e.setHeader(strings.TrimSpace(name), strings.TrimSpace(v))
+		case "referer":
+			e.setHeader("Referer", value)
+		case "user-agent":
+			e.setHeader("User-Agent", value)
+		case "checksum":
+			e.Checksum = value
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func (e *ImportEntry) setHeader(name, value string) {
+	if e.Headers == nil {
+		e.Headers = make(map[string]string)
+	}
+	e.Headers[name] = value
+}
+
+// parseCSV parses rows of `url[,dir[,name]]`, a header row starting with "url" is skipped
+func parseCSV(data []byte, comma rune) ([]ImportEntry, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+	var entries []ImportEntry
+	for i := 0; ; i++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				entries = append(entries, ImportEntry{Line: parseErr.Line, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, err
+		}
+		// FieldPos is only valid for the record just read
+		line, _ := r.FieldPos(0)
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "url") {
+			continue
+		}
+		e := ImportEntry{Line: line, URL: strings.TrimSpace(record[0])}
+		if len(record) > 1 {
+			e.Dir = strings.TrimSpace(record[1])
+		}
+		if len(record) > 2 {
+			e.Name = strings.TrimSpace(record[2])
+		}
+		if e.URL == "" {
+			e.Error = "url is empty"
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,97 @@
+package tool
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseImport(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   []ImportEntry
+	}{
+		{
+			name: "metalink4",
+			input: `<?xml version="1.0" encoding="UTF-8"?>
+<metalink xmlns="urn:ietf:params:xml:ns:metalink">
+  <file name="iso/a.iso">
+    <hash type="sha-256">` + strings.Repeat("a", 64) + `</hash>
+    <url>https://example.com/a.iso</url>
+    <url>https://mirror.example.com/a.iso</url>
+  </file>
+  <file name="b.iso"></file>
+</metalink>`,
+			want: []ImportEntry{
+				{Line: 1, URL: "https://example.com/a.iso", Dir: "iso", Name: "a.iso", Checksum: "sha-256=" + strings.Repeat("a", 64)},
+				{Line: 2, Name: "b.iso", Error: "no url of file b.iso"},
+			},
+		},
+		{
+			name: "metalink3",
+			input: `<metalink version="3.0" xmlns="http://www.metalinker.org/"><files>
+<file name="c.zip"><resources><url type="http">http://example.com/c.zip</url></resources></file>
+</files></metalink>`,
+			want: []ImportEntry{{Line: 1, URL: "http://example.com/c.zip", Name: "c.zip"}},
+		},
+		{
+			name: "aria2",
+			input: `# comment
+https://example.com/1.bin	https://mirror.example.com/1.bin
+  dir=videos
+  out=sub/one.bin
+  header=Cookie: a=b
+  referer=https://example.com/
+magnet:?xt=urn:btih:abc
+ftp://example.com/2.bin
+  checksum=md5=bad
+`,
+			want: []ImportEntry{
+				{Line: 2, URL: "https://example.com/1.bin", Dir: "videos/sub", Name: "one.bin",
+					Headers: map[string]string{"Cookie": "a=b", "Referer": "https://example.com/"}},
+				{Line: 7, URL: "magnet:?xt=urn:btih:abc"},
+				{Line: 8, URL: "ftp://example.com/2.bin", Checksum: "md5=bad", Error: "invalid checksum: invalid md5 hash: bad"},
+			},
+		},
+		{
+			name: "csv",
+			input: `url,dir,name
+https://example.com/1.bin,a/b,one.bin
+https://example.com/2.bin,../x
+file:///etc/passwd
+`,
+			want: []ImportEntry{
+				{Line: 2, URL: "https://example.com/1.bin", Dir: "a/b", Name: "one.bin"},
+				{Line: 3, URL: "https://example.com/2.bin", Dir: "../x", Error: "dir must not be outside the destination: ../x"},
+				{Line: 4, URL: "file:///etc/passwd", Error: "unsupported url scheme: file"},
+			},
+		},
+		{
+			name:   "malformed csv",
+			format: "csv",
+			input: `https://example.com/a"b.bin,x
+https://example.com/2.bin
+`,
+			want: []ImportEntry{
+				{Line: 1, Error: "bare \" in non-quoted-field"},
+				{Line: 2, URL: "https://example.com/2.bin"},
+			},
+		},
+		{
+			name:  "tsv",
+			input: "https://example.com/1.bin\t/music/\n",
+			want:  []ImportEntry{{Line: 1, URL: "https://example.com/1.bin", Dir: "music"}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := ParseImport(tt.format, strings.NewReader(tt.input))
+		if err != nil {
+			t.Fatalf("%s: %+v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, got, tt.want)
+		}
+	}
+}
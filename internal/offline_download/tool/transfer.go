@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/internal/webhook"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -29,6 +31,15 @@ type TransferTask struct {
 	SrcStorageMp string        `json:"src_storage_mp"`
 	DstStorageMp string        `json:"dst_storage_mp"`
 	DeletePolicy DeletePolicy  `json:"delete_policy"`
+	// DstName is the name of the transferred obj, empty means the name of the src
+	DstName string `json:"dst_name,omitempty"`
+}
+
+func (t *TransferTask) dstName() string {
+	if t.DstName != "" {
+		return t.DstName
+	}
+	return filepath.Base(t.SrcObjPath)
 }
 
 func (t *TransferTask) Run() error {
@@ -51,6 +62,7 @@ func (t *TransferTask) GetStatus() string {
 }
 
 func (t *TransferTask) OnSucceeded() {
+	webhook.EmitTask("offline_download_transfer", t)
 	if t.DeletePolicy == DeleteOnUploadSucceed || t.DeletePolicy == DeleteAlways {
 		if t.SrcStorage == nil {
 			removeStdTemp(t)
@@ -61,6 +73,7 @@ func (t *TransferTask) OnSucceeded() {
 }
 
 func (t *TransferTask) OnFailed() {
+	webhook.EmitTask("offline_download_transfer", t)
 	if t.DeletePolicy == DeleteOnUploadFailed || t.DeletePolicy == DeleteAlways {
 		if t.SrcStorage == nil {
 			removeStdTemp(t)
@@ -74,7 +87,8 @@ var (
 	TransferTaskManager *tache.Manager[*TransferTask]
 )
 
-func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy DeletePolicy) error {
+// transferStd uploads what's downloaded in tempDir, a single downloaded obj is named dstName if given
+func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy DeletePolicy, dstName string) error {
 	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get dst storage")
@@ -83,6 +97,9 @@ func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy D
 	if err != nil {
 		return err
 	}
+	if dstName != "" && len(entries) != 1 {
+		return errors.Errorf("can't name %d downloaded objs as %s", len(entries), dstName)
+	}
 	taskCreator, _ := ctx.Value("user").(*model.User)
 	for _, entry := range entries {
 		t := &TransferTask{
@@ -94,6 +111,7 @@ func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy D
 			DstStorage:   dstStorage,
 			DstStorageMp: dstStorage.GetStorage().MountPath,
 			DeletePolicy: deletePolicy,
+			DstName:      dstName,
 		}
 		TransferTaskManager.Add(t)
 	}
@@ -114,7 +132,7 @@ func transferStdPath(t *TransferTask) error {
 		}
 		for _, entry := range entries {
 			srcRawPath := stdpath.Join(t.SrcObjPath, entry.Name())
-			dstObjPath := stdpath.Join(t.DstDirPath, info.Name())
+			dstObjPath := stdpath.Join(t.DstDirPath, t.dstName())
 			t := &TransferTask{
 				TaskExtension: task.TaskExtension{
 					Creator: t.Creator,
@@ -147,7 +165,7 @@ func transferStdFile(t *TransferTask) error {
 	s := &stream.FileStream{
 		Ctx: nil,
 		Obj: &model.Object{
-			Name:     filepath.Base(t.SrcObjPath),
+			Name:     t.dstName(),
 			Size:     info.Size(),
 			Modified: info.ModTime(),
 			IsFolder: false,
@@ -157,7 +175,43 @@ func transferStdFile(t *TransferTask) error {
 		Closers:  utils.NewClosers(rc),
 	}
 	t.SetTotalBytes(info.Size())
-	return op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, s, t.SetProgress)
+	if err = op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, s, t.SetProgress); err != nil {
+		return err
+	}
+	t.Status = "verifying hash"
+	return verifyStdFile(t)
+}
+
+// verifyStdFile compares the hash of the local file with the uploaded one
+// for each standard hash type the dst storage exposes
+func verifyStdFile(t *TransferTask) error {
+	dstPath := stdpath.Join(t.DstDirPath, t.dstName())
+	dstObj, err := op.Get(t.Ctx(), t.DstStorage, dstPath)
+	if errs.IsObjectNotFound(err) {
+		// the uploaded object may not be in the list cache yet
+		if _, err = op.List(t.Ctx(), t.DstStorage, t.DstDirPath, model.ListArgs{Refresh: true}); err == nil {
+			dstObj, err = op.Get(t.Ctx(), t.DstStorage, dstPath)
+		}
+	}
+	if err != nil {
+		return errors.WithMessagef(err, "failed get uploaded [%s] file", dstPath)
+	}
+	src := make(map[*utils.HashType]string)
+	for _, ht := range []*utils.HashType{utils.MD5, utils.SHA1, utils.SHA256} {
+		if dstObj.GetHash().GetHash(ht) == "" {
+			continue
+		}
+		f, err := os.Open(t.SrcObjPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open file %s", t.SrcObjPath)
+		}
+		src[ht], err = utils.HashReader(ht, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return op.VerifyHash(t.Ctx(), t.DstStorage, stdpath.Join(t.DstDirPath, dstObj.GetName()), utils.NewHashInfoByMap(src))
 }
 
 func removeStdTemp(t *TransferTask) {
@@ -260,7 +314,11 @@ func transferObjFile(t *TransferTask) error {
 		return errors.WithMessagef(err, "failed get [%s] stream", t.SrcObjPath)
 	}
 	t.SetTotalBytes(srcFile.GetSize())
-	return op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, ss, t.SetProgress)
+	if err = op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, ss, t.SetProgress); err != nil {
+		return err
+	}
+	t.Status = "verifying hash"
+	return op.VerifyHash(t.Ctx(), t.DstStorage, stdpath.Join(t.DstDirPath, srcFile.GetName()), srcFile.GetHash())
 }
 
 func removeObjTemp(t *TransferTask) {
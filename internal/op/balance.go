@@ -0,0 +1,134 @@
+package op
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	BalanceRoundRobin         = "round_robin"
+	BalanceWeightedRoundRobin = "weighted_round_robin"
+	BalanceLeastConnections   = "least_connections"
+	BalanceFailover           = "failover"
+)
+
+const defaultEjectCooldown = time.Minute
+
+var (
+	balanceMap   generic_sync.MapOf[string, int]
+	ejectedUntil generic_sync.MapOf[string, time.Time]
+	activeConns  generic_sync.MapOf[string, *int64]
+
+	wrrMu      sync.Mutex
+	wrrCurrent = map[string]int{} // current weights of smooth weighted round-robin, key is mount path
+)
+
+// EjectStorage makes the balancer skip the storage for its cool-down period
+func EjectStorage(storage driver.Driver) {
+	cooldown := defaultEjectCooldown
+	if s := storage.GetStorage().EjectCooldown; s > 0 {
+		cooldown = time.Duration(s) * time.Second
+	}
+	log.Warnf("storage [%s] is ejected for %s", storage.GetStorage().MountPath, cooldown)
+	ejectedUntil.Store(storage.GetStorage().MountPath, time.Now().Add(cooldown))
+}
+
+// shouldEject reports whether the failure of the storage should take it out of balancing,
+// errors of the requested object such as not found or denied say nothing about the storage
+func shouldEject(storage driver.Driver, err error) bool {
+	return errs.IsStorageFailure(err) &&
+		GetBalancedStoragesNum(utils.GetActualMountPath(storage.GetStorage().MountPath)) > 1
+}
+
+func isHealthy(storage driver.Driver) bool {
+	if storage.GetStorage().Status != WORK {
+		return false
+	}
+	until, ok := ejectedUntil.Load(storage.GetStorage().MountPath)
+	return !ok || time.Now().After(until)
+}
+
+// TrackActive counts the in-flight calls and proxied downloads of the storage, call the returned func when done
+func TrackActive(storage driver.Driver) func() {
+	n, _ := activeConns.LoadOrStore(storage.GetStorage().MountPath, new(int64))
+	atomic.AddInt64(n, 1)
+	return func() {
+		atomic.AddInt64(n, -1)
+	}
+}
+
+func getActive(storage driver.Driver) int64 {
+	if n, ok := activeConns.Load(storage.GetStorage().MountPath); ok {
+		return atomic.LoadInt64(n)
+	}
+	return 0
+}
+
+// selectStorage selects one of the storages sharing a virtual path by the policy of the first one,
+// unhealthy storages are skipped unless all of them are unhealthy
+func selectStorage(storages []driver.Driver) driver.Driver {
+	healthy := make([]driver.Driver, 0, len(storages))
+	for _, s := range storages {
+		if isHealthy(s) {
+			healthy = append(healthy, s)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = storages
+	}
+	virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
+	switch storages[0].GetStorage().BalancePolicy {
+	case BalanceWeightedRoundRobin:
+		return selectWeighted(healthy)
+	case BalanceLeastConnections:
+		res := healthy[0]
+		for _, s := range healthy[1:] {
+			if getActive(s) < getActive(res) {
+				res = s
+			}
+		}
+		return res
+	case BalanceFailover:
+		sorted := make([]driver.Driver, len(healthy))
+		copy(sorted, healthy)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].GetStorage().Order < sorted[j].GetStorage().Order
+		})
+		return sorted[0]
+	default:
+		i, _ := balanceMap.LoadOrStore(virtualPath, 0)
+		i = (i + 1) % len(healthy)
+		balanceMap.Store(virtualPath, i)
+		return healthy[i]
+	}
+}
+
+// selectWeighted is the smooth weighted round-robin used by nginx
+func selectWeighted(storages []driver.Driver) driver.Driver {
+	wrrMu.Lock()
+	defer wrrMu.Unlock()
+	var best driver.Driver
+	total := 0
+	for _, s := range storages {
+		weight := s.GetStorage().BalanceWeight
+		if weight < 1 {
+			weight = 1
+		}
+		total += weight
+		mountPath := s.GetStorage().MountPath
+		wrrCurrent[mountPath] += weight
+		if best == nil || wrrCurrent[mountPath] > wrrCurrent[best.GetStorage().MountPath] {
+			best = s
+		}
+	}
+	wrrCurrent[best.GetStorage().MountPath] -= total
+	return best
+}
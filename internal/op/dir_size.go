@@ -0,0 +1,45 @@
+package op
+
+import (
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+func ReplaceDirSizes(root string, sizes []model.DirSize) error {
+	return db.ReplaceDirSizes(root, sizes)
+}
+
+// clearDirSizes drops the sizes calculated by du that a write at the paths of storage makes stale
+func clearDirSizes(storage driver.Driver, paths ...string) {
+	for _, path := range paths {
+		path = stdpath.Join(utils.GetActualMountPath(storage.GetStorage().MountPath), path)
+		if err := db.DeleteDirSizes(path); err != nil {
+			log.Errorf("failed clear dir sizes of %s: %+v", path, err)
+		}
+	}
+}
+
+func GetDirSize(path string) (*model.DirSize, error) {
+	return db.GetDirSize(path)
+}
+
+// GetDirSizes returns the cached sizes of the dirs by path, dirs without one are absent
+func GetDirSizes(paths []string) (map[string]model.DirSize, error) {
+	sizes := make(map[string]model.DirSize)
+	if len(paths) == 0 {
+		return sizes, nil
+	}
+	list, err := db.GetDirSizes(paths)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range list {
+		sizes[s.Path] = s
+	}
+	return sizes, nil
+}
@@ -0,0 +1,30 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func ReplaceDuplicateGroups(groups []model.DuplicateGroup) error {
+	return db.ReplaceDuplicateGroups(groups)
+}
+
+func GetDuplicateGroups(pageIndex, pageSize int) ([]model.DuplicateGroup, int64, error) {
+	return db.GetDuplicateGroups(pageIndex, pageSize)
+}
+
+func GetDuplicateGroupById(id uint) (*model.DuplicateGroup, error) {
+	return db.GetDuplicateGroupById(id)
+}
+
+func GetDuplicateFilesByIds(ids []uint) ([]model.DuplicateFile, error) {
+	return db.GetDuplicateFilesByIds(ids)
+}
+
+func GetDuplicateFilesByGroupId(groupId uint) ([]model.DuplicateFile, error) {
+	return db.GetDuplicateFilesByGroupId(groupId)
+}
+
+func UpdateDuplicateFile(f *model.DuplicateFile) error {
+	return db.UpdateDuplicateFile(f)
+}
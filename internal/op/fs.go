@@ -8,6 +8,7 @@ import (
 	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
 	"github.com/alist-org/alist/v3/pkg/singleflight"
@@ -95,6 +96,10 @@ func ClearCache(storage driver.Driver, path string) {
 	listCache.Del(Key(storage, path))
 }
 
+func observeDriverCall(storage driver.Driver, method string, start time.Time, err error) {
+	metrics.ObserveDriverCall(storage.GetStorage().MountPath, storage.Config().Name, method, start, err)
+}
+
 func Key(storage driver.Driver, path string) string {
 	return stdpath.Join(storage.GetStorage().MountPath, utils.FixAndCleanPath(path))
 }
@@ -110,8 +115,10 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 	if !args.Refresh {
 		if files, ok := listCache.Get(key); ok {
 			log.Debugf("use cache when list %s", path)
+			metrics.ListCacheHit()
 			return files, nil
 		}
+		metrics.ListCacheMiss()
 	}
 	dir, err := GetUnwrap(ctx, storage, path)
 	if err != nil {
@@ -122,7 +129,11 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, errors.WithStack(errs.NotFolder)
 	}
 	objs, err, _ := listG.Do(key, func() ([]model.Obj, error) {
+		start := time.Now()
+		done := TrackActive(storage)
 		files, err := storage.List(ctx, dir, args)
+		done()
+		observeDriverCall(storage, "list", start, err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list objs")
 		}
@@ -255,8 +266,15 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return link, file, nil
 	}
 	fn := func() (*model.Link, error) {
+		start := time.Now()
+		done := TrackActive(storage)
 		link, err := storage.Link(ctx, file, args)
+		done()
+		observeDriverCall(storage, "link", start, err)
 		if err != nil {
+			if shouldEject(storage, err) {
+				EjectStorage(storage)
+			}
 			return nil, errors.Wrapf(err, "failed get link")
 		}
 		if link.Expiration != nil {
@@ -277,6 +295,18 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 	return link, file, err
 }
 
+// GetStorageDetails get the total and free space of the storage
+func GetStorageDetails(ctx context.Context, storage driver.Driver) (*model.StorageDetails, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	d, ok := storage.(driver.WithDetails)
+	if !ok {
+		return nil, errs.NotImplement
+	}
+	return d.GetDetails(ctx)
+}
+
 // Other api
 func Other(ctx context.Context, storage driver.Driver, args model.FsOtherArgs) (interface{}, error) {
 	obj, err := GetUnwrap(ctx, storage, args.Path)
@@ -318,6 +348,7 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 					return nil, errors.WithMessagef(err, "failed to get parent dir [%s]", parentPath)
 				}
 
+				start := time.Now()
 				switch s := storage.(type) {
 				case driver.MkdirResult:
 					var newObj model.Obj
@@ -337,6 +368,11 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 				default:
 					return nil, errs.NotImplement
 				}
+				observeDriverCall(storage, "mkdir", start, err)
+				if err == nil {
+					// the dir counts in the sizes of its parents
+					clearDirSizes(storage, path)
+				}
 				return nil, errors.WithStack(err)
 			}
 			return nil, errors.WithMessage(err, "failed to check if dir exists")
@@ -368,6 +404,7 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	}
 	srcDirPath := stdpath.Dir(srcPath)
 
+	start := time.Now()
 	switch s := storage.(type) {
 	case driver.MoveResult:
 		var newObj model.Obj
@@ -391,6 +428,10 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		clearDirSizes(storage, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	}
+	observeDriverCall(storage, "move", start, err)
 	return errors.WithStack(err)
 }
 
@@ -406,6 +447,7 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	srcObj := model.UnwrapObj(srcRawObj)
 	srcDirPath := stdpath.Dir(srcPath)
 
+	start := time.Now()
 	switch s := storage.(type) {
 	case driver.RenameResult:
 		var newObj model.Obj
@@ -425,6 +467,10 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		clearDirSizes(storage, srcPath)
+	}
+	observeDriverCall(storage, "rename", start, err)
 	return errors.WithStack(err)
 }
 
@@ -444,6 +490,7 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 		return errors.WithMessage(err, "failed to get dst dir")
 	}
 
+	start := time.Now()
 	switch s := storage.(type) {
 	case driver.CopyResult:
 		var newObj model.Obj
@@ -463,6 +510,10 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		clearDirSizes(storage, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	}
+	observeDriverCall(storage, "copy", start, err)
 	return errors.WithStack(err)
 }
 
@@ -485,6 +536,7 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 	}
 	dirPath := stdpath.Dir(path)
 
+	start := time.Now()
 	switch s := storage.(type) {
 	case driver.Remove:
 		err = s.Remove(ctx, model.UnwrapObj(rawObj))
@@ -498,6 +550,10 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		clearDirSizes(storage, path)
+	}
+	observeDriverCall(storage, "remove", start, err)
 	return errors.WithStack(err)
 }
 
@@ -546,6 +602,8 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 		up = func(p float64) {}
 	}
 
+	start := time.Now()
+	done := TrackActive(storage)
 	switch s := storage.(type) {
 	case driver.PutResult:
 		var newObj model.Obj
@@ -563,8 +621,14 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 			ClearCache(storage, dstDirPath)
 		}
 	default:
+		done()
 		return errs.NotImplement
 	}
+	done()
+	if err == nil {
+		clearDirSizes(storage, dstPath)
+	}
+	observeDriverCall(storage, "put", start, err)
 	log.Debugf("put file [%s] done", file.GetName())
 	if storage.Config().NoOverwriteUpload && fi != nil && fi.GetSize() > 0 {
 		if err != nil {
@@ -604,6 +668,7 @@ func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url
 	if err != nil {
 		return errors.WithMessagef(err, "failed to put url")
 	}
+	start := time.Now()
 	switch s := storage.(type) {
 	case driver.PutURLResult:
 		var newObj model.Obj
@@ -623,6 +688,10 @@ func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		clearDirSizes(storage, stdpath.Join(dstDirPath, dstName))
+	}
+	observeDriverCall(storage, "put_url", start, err)
 	log.Debugf("put url [%s](%s) done", dstName, url)
 	return errors.WithStack(err)
 }
@@ -0,0 +1,26 @@
+package op
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// VerifyHash compares the hash of the uploaded object with the source hash,
+// nothing is checked if they have no common hash type
+func VerifyHash(ctx context.Context, storage driver.Driver, path string, src utils.HashInfo) error {
+	if len(src.Export()) == 0 {
+		return nil
+	}
+	obj, err := Get(ctx, storage, path)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get uploaded [%s] file", path)
+	}
+	if equal, ok := src.Compare(obj.GetHash()); ok && !equal {
+		return errors.Wrapf(errs.ChecksumMismatch, "src %s, dst %s", src.String(), obj.GetHash().String())
+	}
+	return nil
+}
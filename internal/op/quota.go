@@ -0,0 +1,26 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func ReplaceUploadRecord(r *model.UploadRecord) error {
+	return db.ReplaceUploadRecord(r)
+}
+
+func GetUserUploadedBytes(userId uint) (int64, error) {
+	return db.GetUserUploadedBytes(userId)
+}
+
+func GetPathUploadedBytes(path string) (int64, error) {
+	return db.GetPathUploadedBytes(path)
+}
+
+func DeleteUploadRecords(path string) error {
+	return db.DeleteUploadRecords(path)
+}
+
+func MoveUploadRecords(oldPath, newPath string) error {
+	return db.MoveUploadRecords(oldPath, newPath)
+}
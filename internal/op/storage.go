@@ -120,6 +120,29 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 	return err
 }
 
+// dropDelay is how long the instance replaced by ReinitStorage is kept for the requests using it
+var dropDelay = 10 * time.Minute
+
+// ReinitStorage initializes a new instance of the driver and replaces the old one with it,
+// used to recover the storage from errors such as an expired token.
+// Requests in flight may still be using the old instance, so it's dropped after dropDelay
+func ReinitStorage(ctx context.Context, oldDriver driver.Driver) (driver.Driver, error) {
+	storage := *oldDriver.GetStorage()
+	driverNew, err := GetDriver(storage.Driver)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get driver new")
+	}
+	storageDriver := driverNew()
+	err = initStorage(ctx, storage, storageDriver)
+	time.AfterFunc(dropDelay, func() {
+		if e := oldDriver.Drop(context.Background()); e != nil {
+			log.Errorf("failed drop storage: %+v", e)
+		}
+	})
+	go callStorageHooks("update", storageDriver)
+	return storageDriver, err
+}
+
 func EnableStorage(ctx context.Context, id uint) error {
 	storage, err := db.GetStorageById(id)
 	if err != nil {
@@ -318,8 +341,6 @@ func GetStorageVirtualFilesByPath(prefix string) []model.Obj {
 	return files
 }
 
-var balanceMap generic_sync.MapOf[string, int]
-
 // GetBalancedStorage get storage by path
 func GetBalancedStorage(path string) driver.Driver {
 	path = utils.FixAndCleanPath(path)
@@ -331,10 +352,11 @@ func GetBalancedStorage(path string) driver.Driver {
 	case 1:
 		return storages[0]
 	default:
-		virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
-		i, _ := balanceMap.LoadOrStore(virtualPath, 0)
-		i = (i + 1) % storageNum
-		balanceMap.Store(virtualPath, i)
-		return storages[i]
+		return selectStorage(storages)
 	}
 }
+
+// GetBalancedStoragesNum get the number of storages sharing the mount path of path
+func GetBalancedStoragesNum(path string) int {
+	return len(getStoragesByPath(utils.FixAndCleanPath(path)))
+}
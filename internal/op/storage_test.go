@@ -71,6 +71,19 @@ func TestGetBalancedStorage(t *testing.T) {
 	}
 }
 
+func TestEjectStorage(t *testing.T) {
+	storage, err := op.GetStorageByMountPath("/a/d/e1")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	op.EjectStorage(storage)
+	for i := 0; i < 5; i++ {
+		if mountPath := op.GetBalancedStorage("/a/d/e1").GetStorage().MountPath; mountPath != "/a/d/e1.balance" {
+			t.Errorf("expected: /a/d/e1.balance, got: %s", mountPath)
+		}
+	}
+}
+
 func setupStorages(t *testing.T) {
 	var storages = []model.Storage{
 		{Driver: "Local", MountPath: "/a/b", Order: 0, Addition: `{"root_folder_path":"."}`},
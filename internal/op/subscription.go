@@ -0,0 +1,86 @@
+package op
+
+import (
+	"regexp"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// DefaultSubscriptionInterval is the poll interval in minutes used when none is set
+const DefaultSubscriptionInterval = 30
+
+func validateSubscription(s *model.Subscription) error {
+	if s.URL == "" {
+		return errors.New("subscription url is required")
+	}
+	for _, expr := range []string{s.Include, s.Exclude} {
+		if _, err := regexp.Compile(expr); err != nil {
+			return errors.Wrapf(err, "invalid regexp %s", expr)
+		}
+	}
+	if s.Interval <= 0 {
+		s.Interval = DefaultSubscriptionInterval
+	}
+	s.DstPath = utils.FixAndCleanPath(s.DstPath)
+	return nil
+}
+
+func GetSubscriptionById(id uint) (*model.Subscription, error) {
+	return db.GetSubscriptionById(id)
+}
+
+func GetSubscriptions(pageIndex, pageSize int) ([]model.Subscription, int64, error) {
+	return db.GetSubscriptions(pageIndex, pageSize)
+}
+
+func GetEnabledSubscriptions() ([]model.Subscription, error) {
+	return db.GetEnabledSubscriptions()
+}
+
+func CreateSubscription(s *model.Subscription) error {
+	if err := validateSubscription(s); err != nil {
+		return err
+	}
+	return db.CreateSubscription(s)
+}
+
+func UpdateSubscription(s *model.Subscription) error {
+	if _, err := db.GetSubscriptionById(s.ID); err != nil {
+		return err
+	}
+	if err := validateSubscription(s); err != nil {
+		return err
+	}
+	return db.UpdateSubscription(s)
+}
+
+func UpdateSubscriptionCheck(s *model.Subscription) error {
+	return db.UpdateSubscriptionCheck(s)
+}
+
+func DeleteSubscriptionById(id uint) error {
+	return db.DeleteSubscriptionById(id)
+}
+
+func GetSubscriptionItemById(id uint) (*model.SubscriptionItem, error) {
+	return db.GetSubscriptionItemById(id)
+}
+
+func GetSubscriptionGUIDs(subscriptionId uint) ([]string, error) {
+	return db.GetSubscriptionGUIDs(subscriptionId)
+}
+
+func SaveSubscriptionItem(item *model.SubscriptionItem) error {
+	return db.SaveSubscriptionItem(item)
+}
+
+func GetSubscriptionItems(subscriptionId uint, pageIndex, pageSize int) ([]model.SubscriptionItem, int64, error) {
+	return db.GetSubscriptionItems(subscriptionId, pageIndex, pageSize)
+}
+
+func ClearSubscriptionItems(subscriptionId uint) error {
+	return db.DeleteSubscriptionItems(subscriptionId)
+}
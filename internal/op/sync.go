@@ -0,0 +1,100 @@
+package op
+
+import (
+	"path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// ValidateSyncArgs checks the paths, mode, compare method and patterns of a sync, empty ones get the defaults
+func ValidateSyncArgs(srcPath, dstPath string, mode, compare *string, patterns ...string) error {
+	if utils.IsSubPath(srcPath, dstPath) || utils.IsSubPath(dstPath, srcPath) {
+		return errors.New("src and dst of a sync must not contain each other")
+	}
+	switch *mode {
+	case "":
+		*mode = model.SyncMirror
+	case model.SyncMirror, model.SyncUpdate, model.SyncTwoWay:
+	default:
+		return errors.Errorf("invalid sync mode: %s", *mode)
+	}
+	switch *compare {
+	case "":
+		*compare = model.SyncCompareSizeMtime
+	case model.SyncCompareSizeMtime, model.SyncCompareHash:
+	default:
+		return errors.Errorf("invalid sync compare method: %s", *compare)
+	}
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return errors.Wrapf(err, "invalid pattern %s", p)
+		}
+	}
+	return nil
+}
+
+// SplitPatterns splits the newline separated glob patterns of a sync job
+func SplitPatterns(s string) []string {
+	var patterns []string
+	for _, p := range strings.Split(s, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+func validateSyncJob(j *model.SyncJob) error {
+	j.SrcPath = utils.FixAndCleanPath(j.SrcPath)
+	j.DstPath = utils.FixAndCleanPath(j.DstPath)
+	if j.Interval < 0 {
+		j.Interval = 0
+	}
+	patterns := append(SplitPatterns(j.Include), SplitPatterns(j.Exclude)...)
+	return ValidateSyncArgs(j.SrcPath, j.DstPath, &j.Mode, &j.Compare, patterns...)
+}
+
+func GetSyncJobById(id uint) (*model.SyncJob, error) {
+	return db.GetSyncJobById(id)
+}
+
+func GetSyncJobs(pageIndex, pageSize int) ([]model.SyncJob, int64, error) {
+	return db.GetSyncJobs(pageIndex, pageSize)
+}
+
+func GetEnabledSyncJobs() ([]model.SyncJob, error) {
+	return db.GetEnabledSyncJobs()
+}
+
+func CreateSyncJob(j *model.SyncJob) error {
+	if err := validateSyncJob(j); err != nil {
+		return err
+	}
+	return db.CreateSyncJob(j)
+}
+
+func UpdateSyncJob(j *model.SyncJob) error {
+	old, err := db.GetSyncJobById(j.ID)
+	if err != nil {
+		return err
+	}
+	// the result of the last run is only saved by the run
+	j.LastRun, j.LastTaskID, j.LastError = old.LastRun, old.LastTaskID, old.LastError
+	if err := validateSyncJob(j); err != nil {
+		return err
+	}
+	return db.UpdateSyncJob(j)
+}
+
+// UpdateSyncJobRun saves only the result of the last run, so the edits made meanwhile are kept
+func UpdateSyncJobRun(j *model.SyncJob) error {
+	return db.UpdateSyncJobRun(j)
+}
+
+func DeleteSyncJobById(id uint) error {
+	return db.DeleteSyncJobById(id)
+}
@@ -0,0 +1,27 @@
+package op_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestValidateSyncArgsPaths(t *testing.T) {
+	for _, tt := range []struct {
+		src, dst string
+		isErr    bool
+	}{
+		{"/a", "/b", false},
+		{"/a", "/ab", false},
+		{"/a", "/a", true},
+		{"/a", "/a/b", true},
+		{"/a/b", "/a", true},
+		{"/", "/x", true},
+		{"/x", "/", true},
+	} {
+		mode, compare := "", ""
+		if err := op.ValidateSyncArgs(tt.src, tt.dst, &mode, &compare); (err != nil) != tt.isErr {
+			t.Errorf("ValidateSyncArgs(%s, %s): got %v", tt.src, tt.dst, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func CreateTrashItem(t *model.TrashItem) error {
+	return db.CreateTrashItem(t)
+}
+
+func GetTrashItemById(id uint) (*model.TrashItem, error) {
+	return db.GetTrashItemById(id)
+}
+
+func GetTrashItems(userId uint, pageIndex, pageSize int) ([]model.TrashItem, int64, error) {
+	return db.GetTrashItems(userId, pageIndex, pageSize)
+}
+
+func GetTrashItemsBefore(t time.Time) ([]model.TrashItem, error) {
+	return db.GetTrashItemsBefore(t)
+}
+
+func DeleteTrashItemById(id uint) error {
+	return db.DeleteTrashItemById(id)
+}
@@ -0,0 +1,36 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func CreateFileVersion(v *model.FileVersion) error {
+	return db.CreateFileVersion(v)
+}
+
+func GetFileVersionById(id uint) (*model.FileVersion, error) {
+	return db.GetFileVersionById(id)
+}
+
+func GetFileVersions(path string) ([]model.FileVersion, error) {
+	return db.GetFileVersions(path)
+}
+
+func GetFileVersionsBefore(t time.Time) ([]model.FileVersion, error) {
+	return db.GetFileVersionsBefore(t)
+}
+
+func GetFileVersionsUnder(path string) ([]model.FileVersion, error) {
+	return db.GetFileVersionsUnder(path)
+}
+
+func MoveFileVersions(oldPath, newPath string) error {
+	return db.MoveFileVersions(oldPath, newPath)
+}
+
+func DeleteFileVersionById(id uint) error {
+	return db.DeleteFileVersionById(id)
+}
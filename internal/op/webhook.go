@@ -0,0 +1,78 @@
+package op
+
+import (
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/pkg/errors"
+)
+
+const enabledWebhooksKey = "enabled"
+
+var webhookCache = cache.NewMemCache(cache.WithShards[[]model.Webhook](1))
+var webhookG singleflight.Group[[]model.Webhook]
+
+// GetEnabledWebhooks get all enabled webhooks, the result is cached until any webhook is changed
+func GetEnabledWebhooks() ([]model.Webhook, error) {
+	if webhooks, ok := webhookCache.Get(enabledWebhooksKey); ok {
+		return webhooks, nil
+	}
+	webhooks, err, _ := webhookG.Do(enabledWebhooksKey, func() ([]model.Webhook, error) {
+		_webhooks, err := db.GetEnabledWebhooks()
+		if err != nil {
+			return nil, err
+		}
+		webhookCache.Set(enabledWebhooksKey, _webhooks, cache.WithEx[[]model.Webhook](time.Hour))
+		return _webhooks, nil
+	})
+	return webhooks, err
+}
+
+func GetWebhookById(id uint) (*model.Webhook, error) {
+	return db.GetWebhookById(id)
+}
+
+func GetWebhooks(pageIndex, pageSize int) ([]model.Webhook, int64, error) {
+	return db.GetWebhooks(pageIndex, pageSize)
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	if w.URL == "" {
+		return errors.New("webhook url is required")
+	}
+	if err := db.CreateWebhook(w); err != nil {
+		return err
+	}
+	webhookCache.Del(enabledWebhooksKey)
+	return nil
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	if _, err := db.GetWebhookById(w.ID); err != nil {
+		return err
+	}
+	if err := db.UpdateWebhook(w); err != nil {
+		return err
+	}
+	webhookCache.Del(enabledWebhooksKey)
+	return nil
+}
+
+func DeleteWebhookById(id uint) error {
+	if err := db.DeleteWebhookById(id); err != nil {
+		return err
+	}
+	webhookCache.Del(enabledWebhooksKey)
+	return nil
+}
+
+func GetWebhookDeliveries(webhookId uint, pageIndex, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	return db.GetWebhookDeliveries(webhookId, pageIndex, pageSize)
+}
+
+func ClearWebhookDeliveries(webhookId uint) error {
+	return db.DeleteWebhookDeliveries(webhookId)
+}
@@ -0,0 +1,283 @@
+package provision
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Plan compares the file with the DB and returns the changes to reconcile them
+func Plan(f *File) ([]Change, error) {
+	var changes []Change
+	for _, plan := range []func(*File) ([]Change, error){planStorages, planMetas, planUsers} {
+		res, err := plan(f)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, res...)
+	}
+	return changes, nil
+}
+
+// Apply applies the changes in order
+func Apply(changes []Change) error {
+	var errs []error
+	for _, c := range changes {
+		if err := c.apply(); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "failed %s %s %s", c.Action, c.Kind, c.Key))
+		}
+	}
+	return utils.MergeErrors(errs...)
+}
+
+func toMap(v any) map[string]any {
+	m, _ := normalize(v)
+	return m
+}
+
+// fromMap decodes m onto a copy of v, so the fields hidden from json are kept
+func fromMap[T any](m map[string]any, v T) (T, error) {
+	data, err := utils.Json.Marshal(m)
+	if err != nil {
+		return v, errors.WithStack(err)
+	}
+	return v, errors.WithStack(utils.Json.Unmarshal(data, &v))
+}
+
+// defaults returns the default values of the driver items
+func defaults(items []driver.Item) map[string]any {
+	res := make(map[string]any)
+	for _, item := range items {
+		if item.Default == "" {
+			continue
+		}
+		var v any = item.Default
+		switch item.Type {
+		case "number":
+			if i, err := strconv.ParseInt(item.Default, 10, 64); err == nil {
+				v = i
+			} else if f, err := strconv.ParseFloat(item.Default, 64); err == nil {
+				v = f
+			}
+		case "bool":
+			if b, err := strconv.ParseBool(item.Default); err == nil {
+				v = b
+			}
+		}
+		res[item.Name] = v
+	}
+	return toMap(res)
+}
+
+func planStorages(f *File) ([]Change, error) {
+	olds, _, err := db.GetStorages(1, -1)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]model.Storage)
+	for _, s := range olds {
+		byPath[s.MountPath] = s
+	}
+	var changes []Change
+	declared := make(map[string]struct{})
+	for _, d := range f.Storages {
+		mountPath := getString(d, "mount_path")
+		if mountPath == "" {
+			return nil, errors.New("storage with empty mount_path")
+		}
+		mountPath = utils.FixAndCleanPath(mountPath)
+		declared[mountPath] = struct{}{}
+		old, exists := byPath[mountPath]
+		driverName := getString(d, "driver")
+		if driverName == "" {
+			driverName = old.Driver
+		}
+		driverNew, err := op.GetDriver(driverName)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "storage [%s]", mountPath)
+		}
+		info := op.GetDriverInfoMap()[driverName]
+		d["mount_path"], d["driver"] = mountPath, driverName
+
+		cur, curAddition := defaults(info.Common), defaults(info.Additional)
+		if exists {
+			cur = toMap(old)
+			if old.Driver == driverName {
+				_ = utils.Json.UnmarshalFromString(old.Addition, &curAddition)
+			}
+		}
+		fields := overlay(cur, d, "", "id", "addition", "status", "modified")
+		addition, _ := d["addition"].(map[string]any)
+		fields = append(fields, overlay(curAddition, addition, "addition.")...)
+		if cur["addition"], err = utils.Json.MarshalToString(curAddition); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		storage, err := fromMap(cur, old)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "storage [%s]", mountPath)
+		}
+		if err = utils.Json.UnmarshalFromString(storage.Addition, driverNew().GetAddition()); err != nil {
+			return nil, errors.Wrapf(err, "storage [%s] has invalid addition", mountPath)
+		}
+		if exists && len(fields) == 0 {
+			continue
+		}
+		c := Change{Kind: "storage", Key: mountPath, Action: Create, Fields: fields}
+		if exists {
+			c.Action = Update
+		}
+		c.apply = func() error {
+			storage.Modified = time.Now()
+			if exists {
+				return db.UpdateStorage(&storage)
+			}
+			storage.ID = 0
+			return db.CreateStorage(&storage)
+		}
+		changes = append(changes, c)
+	}
+	if f.Prune {
+		for _, s := range olds {
+			if _, ok := declared[s.MountPath]; !ok {
+				id := s.ID
+				changes = append(changes, Change{Kind: "storage", Key: s.MountPath, Action: Delete, apply: func() error {
+					return db.DeleteStorageById(id)
+				}})
+			}
+		}
+	}
+	return changes, nil
+}
+
+func planMetas(f *File) ([]Change, error) {
+	olds, _, err := db.GetMetas(1, -1)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]model.Meta)
+	for _, m := range olds {
+		byPath[m.Path] = m
+	}
+	var changes []Change
+	declared := make(map[string]struct{})
+	for _, d := range f.Metas {
+		path := getString(d, "path")
+		if path == "" {
+			return nil, errors.New("meta with empty path")
+		}
+		path = utils.FixAndCleanPath(path)
+		declared[path] = struct{}{}
+		d["path"] = path
+		old, exists := byPath[path]
+		cur := map[string]any{}
+		if exists {
+			cur = toMap(old)
+		}
+		fields := overlay(cur, d, "", "id")
+		meta, err := fromMap(cur, old)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "meta [%s]", path)
+		}
+		if exists && len(fields) == 0 {
+			continue
+		}
+		c := Change{Kind: "meta", Key: path, Action: Create, Fields: fields}
+		if exists {
+			c.Action = Update
+		}
+		c.apply = func() error {
+			if exists {
+				return op.UpdateMeta(&meta)
+			}
+			meta.ID = 0
+			return op.CreateMeta(&meta)
+		}
+		changes = append(changes, c)
+	}
+	if f.Prune {
+		for _, m := range olds {
+			if _, ok := declared[m.Path]; !ok {
+				id := m.ID
+				changes = append(changes, Change{Kind: "meta", Key: m.Path, Action: Delete, apply: func() error {
+					return op.DeleteMetaById(id)
+				}})
+			}
+		}
+	}
+	return changes, nil
+}
+
+func planUsers(f *File) ([]Change, error) {
+	olds, _, err := db.GetUsers(1, -1)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]model.User)
+	for _, u := range olds {
+		byName[u.Username] = u
+	}
+	var changes []Change
+	declared := make(map[string]struct{})
+	for _, d := range f.Users {
+		username := getString(d, "username")
+		if username == "" {
+			return nil, errors.New("user with empty username")
+		}
+		declared[username] = struct{}{}
+		old, exists := byName[username]
+		password := getString(d, "password")
+		if !exists && password == "" {
+			return nil, errors.Errorf("password is required to create user [%s]", username)
+		}
+		cur := map[string]any{}
+		skip := []string{"id", "password"}
+		if exists {
+			cur = toMap(old)
+			if old.IsAdmin() || old.IsGuest() {
+				skip = append(skip, "role")
+			}
+		}
+		fields := overlay(cur, d, "", skip...)
+		user, err := fromMap(cur, old)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "user [%s]", username)
+		}
+		user.Password = ""
+		if password != "" && (!exists || old.ValidateRawPassword(password) != nil) {
+			user.SetPassword(password)
+			fields = append(fields, "password")
+		}
+		if exists && len(fields) == 0 {
+			continue
+		}
+		c := Change{Kind: "user", Key: username, Action: Create, Fields: fields}
+		if exists {
+			c.Action = Update
+		}
+		c.apply = func() error {
+			if exists {
+				return op.UpdateUser(&user)
+			}
+			user.ID = 0
+			return op.CreateUser(&user)
+		}
+		changes = append(changes, c)
+	}
+	if f.Prune {
+		for _, u := range olds {
+			if _, ok := declared[u.Username]; !ok && !u.IsAdmin() && !u.IsGuest() {
+				id := u.ID
+				changes = append(changes, Change{Kind: "user", Key: u.Username, Action: Delete, apply: func() error {
+					return op.DeleteUserById(id)
+				}})
+			}
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,146 @@
+package provision
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the declarative configuration, the items are kept as maps so that
+// only the declared fields are reconciled
+type File struct {
+	// Prune deletes the storages, metas and users which are not declared,
+	// the admin and the guest are never deleted
+	Prune    bool             `yaml:"prune"`
+	Storages []map[string]any `yaml:"storages"`
+	Metas    []map[string]any `yaml:"metas"`
+	Users    []map[string]any `yaml:"users"`
+}
+
+var envRegexp = regexp.MustCompile(`\$\{(\w+)}`)
+
+// Load reads the file, ${VAR} in values is replaced with the environment variable
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var f File
+	if err = yaml.Unmarshal(data, &f); err != nil {
+		return nil, errors.Wrapf(err, "failed parse %s", path)
+	}
+	var missing []string
+	expand := func(s string) string {
+		return envRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			name := envRegexp.FindStringSubmatch(m)[1]
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				missing = append(missing, name)
+			}
+			return v
+		})
+	}
+	for _, items := range [][]map[string]any{f.Storages, f.Metas, f.Users} {
+		for i := range items {
+			normalized, err := normalize(expandValues(items[i], expand))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = normalized
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.Errorf("environment variables are not set: %s", strings.Join(missing, ", "))
+	}
+	return &f, nil
+}
+
+func expandValues(v any, expand func(string) string) map[string]any {
+	res := make(map[string]any)
+	for k, v := range v.(map[string]any) {
+		res[k] = expandValue(v, expand)
+	}
+	return res
+}
+
+func expandValue(v any, expand func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return expand(v)
+	case map[string]any:
+		return expandValues(v, expand)
+	case []any:
+		res := make([]any, len(v))
+		for i := range v {
+			res[i] = expandValue(v[i], expand)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// normalize converts the values to what json produces, so they can be compared with the DB
+func normalize(v any) (map[string]any, error) {
+	data, err := utils.Json.Marshal(v)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var res map[string]any
+	return res, errors.WithStack(utils.Json.Unmarshal(data, &res))
+}
+
+// overlay sets the declared fields on the existing ones and returns the changed field names
+func overlay(existing, declared map[string]any, prefix string, skip ...string) []string {
+	var changed []string
+	for k, v := range declared {
+		if utils.SliceContains(skip, k) {
+			continue
+		}
+		if old, ok := existing[k]; !ok || !reflect.DeepEqual(old, v) {
+			changed = append(changed, prefix+k)
+		}
+		existing[k] = v
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func getString(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+)
+
+// Change describes a change of an item, values are never included since they may be secrets
+type Change struct {
+	Kind   string
+	Key    string
+	Action string
+	Fields []string
+
+	apply func() error
+}
+
+func (c Change) String() string {
+	switch c.Action {
+	case Create:
+		return fmt.Sprintf("+ %s %s", c.Kind, c.Key)
+	case Delete:
+		return fmt.Sprintf("- %s %s", c.Kind, c.Key)
+	default:
+		return fmt.Sprintf("~ %s %s: %s", c.Kind, c.Key, strings.Join(c.Fields, ", "))
+	}
+}
@@ -0,0 +1,45 @@
+package provision
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "provision.yml")
+	err := os.WriteFile(path, []byte(`
+storages:
+  - mount_path: /local
+    driver: Local
+    order: 1
+    addition:
+      root_folder_path: /data
+      token: ${PROVISION_TEST_TOKEN}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Load(path); err == nil {
+		t.Fatal("expect error for missing env")
+	}
+	t.Setenv("PROVISION_TEST_TOKEN", "secret")
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addition := f.Storages[0]["addition"].(map[string]any)
+	if addition["token"] != "secret" {
+		t.Errorf("env is not expanded: %v", addition["token"])
+	}
+
+	existing := map[string]any{"mount_path": "/local", "order": float64(0), "remark": "kept"}
+	changed := overlay(existing, f.Storages[0], "", "addition")
+	if !reflect.DeepEqual(changed, []string{"driver", "order"}) {
+		t.Errorf("unexpected changed fields: %v", changed)
+	}
+	if existing["remark"] != "kept" || existing["order"] != float64(1) {
+		t.Errorf("unexpected overlay result: %v", existing)
+	}
+}
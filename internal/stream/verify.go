@@ -0,0 +1,47 @@
+package stream
+
+import (
+	"encoding/hex"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// VerifyReader hashes the bytes read and returns errs.ChecksumMismatch instead of io.EOF
+// if the hash differs from the expected one, so that the upload reading it fails.
+// The hash of a client is only checked by it, it's not given to the driver as HashInfo
+// since rapid upload drivers would trust it without reading the body
+type VerifyReader struct {
+	r        io.Reader
+	h        hash.Hash
+	expected string
+	size     int64 // checked once size bytes are read, size < 0 means checking at io.EOF
+	read     int64
+	checked  bool
+}
+
+func NewVerifyReader(r io.Reader, ht *utils.HashType, expected string, size int64) *VerifyReader {
+	return &VerifyReader{
+		r:        r,
+		h:        ht.NewFunc(),
+		expected: expected,
+		size:     size,
+	}
+}
+
+func (v *VerifyReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.h.Write(p[:n])
+	v.read += int64(n)
+	if !v.checked && (err == io.EOF || (v.size >= 0 && v.read >= v.size)) {
+		v.checked = true
+		if sum := hex.EncodeToString(v.h.Sum(nil)); !strings.EqualFold(sum, v.expected) {
+			return n, errors.Wrapf(errs.ChecksumMismatch, "expected %s, got %s", v.expected, sum)
+		}
+	}
+	return n, err
+}
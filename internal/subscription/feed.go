@@ -0,0 +1,118 @@
+package subscription
+
+import (
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Item is an entry of a feed that has something to download
+type Item struct {
+	GUID  string
+	Title string
+	URL   string
+}
+
+type rss struct {
+	Channel struct {
+		Items []struct {
+			Title     string `xml:"title"`
+			Link      string `xml:"link"`
+			GUID      string `xml:"guid"`
+			Enclosure struct {
+				URL string `xml:"url,attr"`
+			} `xml:"enclosure"`
+			Description string `xml:"description"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atom struct {
+	Entries []struct {
+		ID    string `xml:"id"`
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary string `xml:"summary"`
+		Content string `xml:"content"`
+	} `xml:"entry"`
+}
+
+var magnetReg = regexp.MustCompile(`magnet:\?xt=urn:[a-zA-Z0-9]+:[a-zA-Z0-9]+[^\s"'<>]*`)
+
+// downloadURL picks the url to download of an item, enclosures come first,
+// then magnet links in the link or the text of the item
+func downloadURL(enclosure string, links []string, texts ...string) string {
+	if enclosure != "" {
+		return enclosure
+	}
+	for _, link := range links {
+		if strings.HasPrefix(link, "magnet:") {
+			return link
+		}
+	}
+	for _, text := range texts {
+		if m := magnetReg.FindString(text); m != "" {
+			return strings.ReplaceAll(m, "&amp;", "&")
+		}
+	}
+	return ""
+}
+
+// ParseFeed parses a RSS or Atom feed, items without anything to download are skipped
+func ParseFeed(r io.Reader) ([]Item, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var root struct {
+		XMLName xml.Name
+	}
+	if err = xml.Unmarshal(data, &root); err != nil {
+		return nil, errors.Wrap(err, "invalid feed")
+	}
+	var items []Item
+	add := func(guid, title, url string) {
+		if url == "" {
+			return
+		}
+		if guid == "" {
+			guid = url
+		}
+		items = append(items, Item{GUID: guid, Title: strings.TrimSpace(title), URL: url})
+	}
+	switch root.XMLName.Local {
+	case "rss":
+		var feed rss
+		if err = xml.Unmarshal(data, &feed); err != nil {
+			return nil, errors.Wrap(err, "invalid rss feed")
+		}
+		for _, it := range feed.Channel.Items {
+			add(it.GUID, it.Title, downloadURL(it.Enclosure.URL, []string{it.Link}, it.Description))
+		}
+	case "feed":
+		var feed atom
+		if err = xml.Unmarshal(data, &feed); err != nil {
+			return nil, errors.Wrap(err, "invalid atom feed")
+		}
+		for _, e := range feed.Entries {
+			var enclosure string
+			var links []string
+			for _, l := range e.Links {
+				if l.Rel == "enclosure" && enclosure == "" {
+					enclosure = l.Href
+				}
+				links = append(links, l.Href)
+			}
+			add(e.ID, e.Title, downloadURL(enclosure, links, e.Summary, e.Content))
+		}
+	default:
+		return nil, errors.Errorf("unsupported feed type: %s", root.XMLName.Local)
+	}
+	return items, nil
+}
@@ -0,0 +1,58 @@
+package subscription
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+		want []Item
+	}{
+		{
+			name: "rss",
+			feed: `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<item><title>Show 02</title><guid>g2</guid><enclosure url="https://example.com/2.torrent" type="application/x-bittorrent"/></item>
+<item><title> Show 01 </title><link>https://example.com/1</link>
+<description>get it &lt;a href="magnet:?xt=urn:btih:abc&amp;amp;dn=one"&gt;here&lt;/a&gt;</description></item>
+<item><title>News</title><link>https://example.com/news</link></item>
+</channel></rss>`,
+			want: []Item{
+				{GUID: "g2", Title: "Show 02", URL: "https://example.com/2.torrent"},
+				{GUID: "magnet:?xt=urn:btih:abc&dn=one", Title: "Show 01", URL: "magnet:?xt=urn:btih:abc&dn=one"},
+			},
+		},
+		{
+			name: "atom",
+			feed: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<entry><id>e1</id><title>Release 1</title><link href="https://example.com/r1"/><link rel="enclosure" href="https://example.com/r1.zip"/></entry>
+<entry><id>e2</id><title>Release 2</title><link href="magnet:?xt=urn:btih:def"/></entry>
+</feed>`,
+			want: []Item{
+				{GUID: "e1", Title: "Release 1", URL: "https://example.com/r1.zip"},
+				{GUID: "e2", Title: "Release 2", URL: "magnet:?xt=urn:btih:def"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		items, err := ParseFeed(strings.NewReader(tt.feed))
+		if err != nil {
+			t.Fatalf("%s: %+v", tt.name, err)
+		}
+		if len(items) != len(tt.want) {
+			t.Fatalf("%s: got %d items, want %d: %+v", tt.name, len(items), len(tt.want), items)
+		}
+		for i := range items {
+			if items[i] != tt.want[i] {
+				t.Errorf("%s: item %d = %+v, want %+v", tt.name, i, items[i], tt.want[i])
+			}
+		}
+	}
+	if _, err := ParseFeed(strings.NewReader(`<html></html>`)); err == nil {
+		t.Error("expected error for non-feed document")
+	}
+}
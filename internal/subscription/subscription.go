@@ -0,0 +1,210 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxFeedSize limits the size of a feed to read
+const maxFeedSize = 10 * 1024 * 1024
+
+var (
+	cronsMu sync.Mutex
+	crons   = make(map[uint]*cron.Cron)
+	client  = &http.Client{Timeout: 30 * time.Second}
+	// checkLocks keeps a manual check and a scheduled one of the same subscription from both enqueuing the new items
+	checkLocks generic_sync.MapOf[uint, *sync.Mutex]
+)
+
+// Start schedules polling of all enabled subscriptions
+func Start() {
+	subscriptions, err := op.GetEnabledSubscriptions()
+	if err != nil {
+		log.Errorf("failed get subscriptions: %+v", err)
+		return
+	}
+	for i := range subscriptions {
+		Schedule(&subscriptions[i])
+	}
+}
+
+// Schedule (re)starts polling the subscription with its interval, disabled ones are stopped
+func Schedule(s *model.Subscription) {
+	cronsMu.Lock()
+	defer cronsMu.Unlock()
+	if c, ok := crons[s.ID]; ok {
+		c.Stop()
+		delete(crons, s.ID)
+	}
+	if s.Disabled || s.Interval <= 0 {
+		return
+	}
+	id := s.ID
+	c := cron.NewCron(time.Duration(s.Interval) * time.Minute)
+	c.Do(func() {
+		s, err := op.GetSubscriptionById(id)
+		if err != nil {
+			log.Errorf("failed get subscription %d: %+v", id, err)
+			return
+		}
+		if _, err = Check(context.Background(), s); err != nil {
+			log.Warnf("failed check subscription [%s]: %+v", s.Name, err)
+		}
+	})
+	crons[id] = c
+}
+
+// Unschedule stops polling the subscription
+func Unschedule(id uint) {
+	cronsMu.Lock()
+	defer cronsMu.Unlock()
+	if c, ok := crons[id]; ok {
+		c.Stop()
+		delete(crons, id)
+	}
+	checkLocks.Delete(id)
+}
+
+func compile(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// Check fetches the feed and enqueues the matched items that haven't been seen, returns the new items
+func Check(ctx context.Context, s *model.Subscription) ([]model.SubscriptionItem, error) {
+	mu, _ := checkLocks.LoadOrStore(s.ID, &sync.Mutex{})
+	mu.Lock()
+	defer mu.Unlock()
+	items, err := fetch(ctx, s.URL)
+	s.LastCheck = time.Now()
+	s.LastError = ""
+	if err != nil {
+		s.LastError = err.Error()
+	}
+	if err := op.UpdateSubscriptionCheck(s); err != nil {
+		log.Errorf("failed update subscription [%s]: %+v", s.Name, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	include, err := compile(s.Include)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid include regexp")
+	}
+	exclude, err := compile(s.Exclude)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid exclude regexp")
+	}
+	guids, err := op.GetSubscriptionGUIDs(s.ID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(guids))
+	for _, guid := range guids {
+		seen[guid] = struct{}{}
+	}
+	var added []model.SubscriptionItem
+	// feeds list the newest first, enqueue in the order of publishing
+	for i := len(items) - 1; i >= 0; i-- {
+		it := items[i]
+		if _, ok := seen[it.GUID]; ok {
+			continue
+		}
+		if include != nil && !include.MatchString(it.Title) {
+			continue
+		}
+		if exclude != nil && exclude.MatchString(it.Title) {
+			continue
+		}
+		seen[it.GUID] = struct{}{}
+		item := &model.SubscriptionItem{
+			SubscriptionID: s.ID,
+			GUID:           it.GUID,
+			Title:          it.Title,
+			URL:            it.URL,
+		}
+		if err := enqueue(ctx, s, item); err != nil {
+			log.Warnf("failed add offline download of [%s] from subscription [%s]: %+v", it.Title, s.Name, err)
+		}
+		added = append(added, *item)
+	}
+	return added, nil
+}
+
+// Retrigger enqueues an item of the history again
+func Retrigger(ctx context.Context, itemId uint) (*model.SubscriptionItem, error) {
+	item, err := op.GetSubscriptionItemById(itemId)
+	if err != nil {
+		return nil, err
+	}
+	s, err := op.GetSubscriptionById(item.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return item, enqueue(ctx, s, item)
+}
+
+// enqueue adds the offline download of the item as admin and records the result
+func enqueue(ctx context.Context, s *model.Subscription, item *model.SubscriptionItem) error {
+	err := func() error {
+		admin, err := op.GetAdmin()
+		if err != nil {
+			return err
+		}
+		t, err := tool.AddURL(context.WithValue(ctx, "user", admin), &tool.AddURLArgs{
+			URL:          item.URL,
+			DstDirPath:   s.DstPath,
+			Tool:         s.Tool,
+			DeletePolicy: tool.DeletePolicy(s.DeletePolicy),
+		})
+		if err != nil {
+			return err
+		}
+		item.TaskID = ""
+		if t != nil {
+			item.TaskID = t.GetID()
+		}
+		return nil
+	}()
+	item.Status = model.SubscriptionItemAdded
+	item.Error = ""
+	if err != nil {
+		item.Status = model.SubscriptionItemFailed
+		item.Error = err.Error()
+	}
+	if err := op.SaveSubscriptionItem(item); err != nil {
+		log.Errorf("failed save subscription item: %+v", err)
+	}
+	return err
+}
+
+func fetch(ctx context.Context, url string) ([]Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed fetch feed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed fetch feed, http status code %d", resp.StatusCode)
+	}
+	return ParseFeed(io.LimitReader(resp.Body, maxFeedSize))
+}
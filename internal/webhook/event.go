@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/google/uuid"
+)
+
+const (
+	FileUploaded     = "file.uploaded"
+	FileRemoved      = "file.removed"
+	FileMoved        = "file.moved"
+	FileCopied       = "file.copied"
+	FileRenamed      = "file.renamed"
+	DirCreated       = "dir.created"
+	TaskSucceeded    = "task.succeeded"
+	TaskFailed       = "task.failed"
+	StorageInitError = "storage.init_error"
+	StorageUnhealthy = "storage.unhealthy"
+	StorageRecovered = "storage.recovered"
+	UserLogin        = "user.login"
+	Ping             = "ping"
+)
+
+// Events lists all events that can be subscribed
+var Events = []string{
+	FileUploaded, FileRemoved, FileMoved, FileCopied, FileRenamed, DirCreated,
+	TaskSucceeded, TaskFailed, StorageInitError, StorageUnhealthy, StorageRecovered, UserLogin,
+}
+
+type Event struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+func newEvent(event string, data interface{}) Event {
+	return Event{
+		ID:        uuid.NewString(),
+		Event:     event,
+		Timestamp: time.Now().Unix(),
+		Data:      data,
+	}
+}
+
+type FileData struct {
+	Path    string `json:"path"`
+	DstPath string `json:"dst_path,omitempty"`
+	User    string `json:"user,omitempty"`
+}
+
+type TaskData struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Creator string `json:"creator,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type StorageData struct {
+	ID        uint   `json:"id"`
+	MountPath string `json:"mount_path"`
+	Driver    string `json:"driver"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+type LoginData struct {
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+	Method   string `json:"method"`
+}
+
+// EmitTask emits task.succeeded or task.failed according to the error of the task
+func EmitTask(typ string, t task.TaskExtensionInfo) {
+	data := TaskData{
+		ID:   t.GetID(),
+		Type: typ,
+		Name: t.GetName(),
+	}
+	if t.GetCreator() != nil {
+		data.Creator = t.GetCreator().Username
+	}
+	if err := t.GetErr(); err != nil {
+		data.Error = err.Error()
+		Emit(TaskFailed, data)
+		return
+	}
+	Emit(TaskSucceeded, data)
+}
@@ -0,0 +1,24 @@
+package webhook
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func init() {
+	op.RegisterStorageHook(func(typ string, storage driver.Driver) {
+		if typ != "add" && typ != "update" {
+			return
+		}
+		s := storage.GetStorage()
+		if s.Disabled || s.Status == op.WORK {
+			return
+		}
+		Emit(StorageInitError, StorageData{
+			ID:        s.ID,
+			MountPath: s.MountPath,
+			Driver:    s.Driver,
+			Status:    s.Status,
+		})
+	})
+}
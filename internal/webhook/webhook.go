@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderEvent     = "X-Alist-Event"
+	HeaderDelivery  = "X-Alist-Delivery"
+	HeaderSignature = "X-Alist-Signature"
+
+	defaultTimeout    = 10 * time.Second
+	maxBackoff        = 5 * time.Minute
+	maxResponseLength = 4096
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+var (
+	lastPrune time.Time
+	pruneMu   sync.Mutex
+)
+
+// Emit sends the event to all subscribed webhooks asynchronously
+func Emit(event string, data interface{}) {
+	webhooks, err := op.GetEnabledWebhooks()
+	if err != nil {
+		log.Errorf("failed get webhooks: %+v", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+	e := newEvent(event, data)
+	for i := range webhooks {
+		w := webhooks[i]
+		if !w.Subscribed(event) {
+			continue
+		}
+		go Deliver(context.Background(), &w, e)
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver posts the event to the webhook, retries with exponential backoff
+// and records the delivery result
+func Deliver(ctx context.Context, w *model.Webhook, e Event) *model.WebhookDelivery {
+	body, err := utils.Json.Marshal(e)
+	delivery := &model.WebhookDelivery{
+		WebhookID: w.ID,
+		EventID:   e.ID,
+		Event:     e.Event,
+		Payload:   string(body),
+		CreatedAt: time.Now(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+		saveDelivery(delivery)
+		return delivery
+	}
+	start := time.Now()
+	backoff := time.Second
+outer:
+	for attempt := 0; attempt <= w.MaxRetry; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				delivery.Error = ctx.Err().Error()
+				break outer
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+		delivery.Attempts = attempt + 1
+		delivery.StatusCode, delivery.Response, err = post(ctx, w, e, body)
+		if err == nil {
+			delivery.Success = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+		log.Warnf("failed deliver webhook [%s] event [%s], attempt %d: %s", w.Name, e.Event, attempt+1, err)
+	}
+	delivery.Duration = time.Since(start).Milliseconds()
+	saveDelivery(delivery)
+	return delivery
+}
+
+func post(ctx context.Context, w *model.Webhook, e Event, body []byte) (int, string, error) {
+	timeout := defaultTimeout
+	if w.Timeout > 0 {
+		timeout = time.Duration(w.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AList-Webhook")
+	req.Header.Set(HeaderEvent, e.Event)
+	req.Header.Set(HeaderDelivery, e.ID)
+	if w.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(w.Secret, body))
+	}
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+	resp, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseLength))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, string(resp), errors.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return res.StatusCode, string(resp), nil
+}
+
+func saveDelivery(d *model.WebhookDelivery) {
+	if err := db.CreateWebhookDelivery(d); err != nil {
+		log.Errorf("failed save webhook delivery: %+v", err)
+	}
+	pruneMu.Lock()
+	defer pruneMu.Unlock()
+	if time.Since(lastPrune) < time.Hour {
+		return
+	}
+	lastPrune = time.Now()
+	if err := db.DeleteWebhookDeliveriesBefore(time.Now().Add(-deliveryRetention)); err != nil {
+		log.Errorf("failed prune webhook deliveries: %+v", err)
+	}
+}
+
+// Test sends a ping event to the webhook synchronously
+func Test(ctx context.Context, w *model.Webhook) *model.WebhookDelivery {
+	return Deliver(ctx, w, newEvent(Ping, nil))
+}
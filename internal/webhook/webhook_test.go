@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '{"event":"ping"}' | openssl dgst -sha256 -hmac secret
+	got := Sign("secret", []byte(`{"event":"ping"}`))
+	want := "4f4bb3a54e99c4a20e243485229f9b08c66e09104ba6f79c23ce647242a4ce84"
+	if got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+	if got == Sign("other", []byte(`{"event":"ping"}`)) {
+		t.Errorf("signature should depend on secret")
+	}
+}
+
+func TestSubscribed(t *testing.T) {
+	cases := []struct {
+		events string
+		event  string
+		want   bool
+	}{
+		{"", FileUploaded, true},
+		{"*", TaskFailed, true},
+		{"file.uploaded,file.removed", FileRemoved, true},
+		{"file.uploaded, file.removed", FileMoved, false},
+		{"file.*", FileMoved, true},
+		{"file.*", TaskSucceeded, false},
+	}
+	for _, c := range cases {
+		w := model.Webhook{Events: c.events}
+		if got := w.Subscribed(c.event); got != c.want {
+			t.Errorf("Subscribed(%q) with events %q = %v, want %v", c.event, c.events, got, c.want)
+		}
+	}
+	w := model.Webhook{Disabled: true}
+	if w.Subscribed(FileUploaded) {
+		t.Errorf("disabled webhook should not subscribe any event")
+	}
+}
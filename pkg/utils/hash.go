@@ -10,6 +10,7 @@ import (
 	"errors"
 	"hash"
 	"io"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	log "github.com/sirupsen/logrus"
@@ -86,6 +87,16 @@ var (
 	SHA256 = RegisterHash("sha256", "SHA-256", 64, sha256.New)
 )
 
+// GetHashByName returns the registered hash type by name or alias, case-insensitive
+func GetHashByName(name string) *HashType {
+	for _, ht := range Supported {
+		if strings.EqualFold(ht.Name, name) || strings.EqualFold(ht.Alias, name) {
+			return ht
+		}
+	}
+	return nil
+}
+
 // HashData get hash of one hashType
 func HashData(hashType *HashType, data []byte, params ...any) string {
 	h := hashType.NewFunc(params...)
@@ -223,6 +234,21 @@ func (hi HashInfo) GetHash(ht *HashType) string {
 	return hi.h[ht]
 }
 
+// Compare compares the hashes of all common hash types,
+// ok is false if there is no common hash type to compare
+func (hi HashInfo) Compare(other HashInfo) (equal, ok bool) {
+	for ht, v := range hi.h {
+		if v == "" || other.h[ht] == "" {
+			continue
+		}
+		if !strings.EqualFold(v, other.h[ht]) {
+			return false, true
+		}
+		ok = true
+	}
+	return ok, ok
+}
+
 func (hi HashInfo) Export() map[*HashType]string {
 	return hi.h
 }
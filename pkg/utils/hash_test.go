@@ -64,3 +64,17 @@ func TestMultiHasher(t *testing.T) {
 
 	}
 }
+
+func TestHashInfoCompare(t *testing.T) {
+	a := NewHashInfoByMap(map[*HashType]string{MD5: "BF13FC19E5151AC57D4252E0E0F87ABE", SHA1: "3ab6"})
+	b := NewHashInfo(MD5, "bf13fc19e5151ac57d4252e0e0f87abe")
+	equal, ok := a.Compare(b)
+	assert.True(t, ok)
+	assert.True(t, equal)
+	_, ok = a.Compare(NewHashInfo(SHA256, "c839"))
+	assert.False(t, ok)
+	equal, ok = a.Compare(NewHashInfo(SHA1, "ffff"))
+	assert.True(t, ok)
+	assert.False(t, equal)
+	assert.Equal(t, SHA1, GetHashByName("SHA-1"))
+}
@@ -0,0 +1,29 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// ParseFileHash parses the expected hash of an uploaded file,
+// both "md5=<hex>" and "SHA1:<hex>" (OC-Checksum) are accepted
+func ParseFileHash(header string) (*utils.HashType, string, error) {
+	name, sum, ok := strings.Cut(header, "=")
+	if !ok {
+		name, sum, ok = strings.Cut(header, ":")
+	}
+	if !ok || sum == "" {
+		return nil, "", errors.Errorf("invalid hash: %s", header)
+	}
+	ht := utils.GetHashByName(strings.TrimSpace(name))
+	if ht == nil {
+		return nil, "", errors.WithStack(utils.ErrUnsupported)
+	}
+	sum = strings.ToLower(strings.TrimSpace(sum))
+	if len(sum) != ht.Width {
+		return nil, "", errors.Errorf("invalid %s hash: %s", ht.Name, sum)
+	}
+	return ht, sum, nil
+}
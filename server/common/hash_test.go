@@ -0,0 +1,23 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func TestParseFileHash(t *testing.T) {
+	ht, sum, err := ParseFileHash("md5=BF13FC19E5151AC57D4252E0E0F87ABE")
+	if err != nil || ht != utils.MD5 || sum != "bf13fc19e5151ac57d4252e0e0f87abe" {
+		t.Errorf("unexpected result: %v %s %v", ht, sum, err)
+	}
+	ht, _, err = ParseFileHash("SHA1:3ab6543c08a75f292a5ecedac87ec41642d12166")
+	if err != nil || ht != utils.SHA1 {
+		t.Errorf("unexpected result: %v %v", ht, err)
+	}
+	for _, header := range []string{"md5", "md5=abc", "crc32=00000000"} {
+		if _, _, err = ParseFileHash(header); err == nil {
+			t.Errorf("%s should be invalid", header)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/net"
 	"github.com/alist-org/alist/v3/internal/stream"
@@ -16,6 +17,7 @@ import (
 )
 
 func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.Obj) error {
+	w = metrics.CountingWriter{ResponseWriter: w}
 	if link.MFile != nil {
 		defer link.MFile.Close()
 		attachFileName(w, file)
@@ -6,6 +6,7 @@ import (
 	ftpserver "github.com/KirCute/ftpserverlib-pasvportmap"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/spf13/afero"
 	"os"
@@ -21,13 +22,24 @@ func NewAferoAdapter(ctx context.Context) *AferoAdapter {
 	return &AferoAdapter{ctx: ctx}
 }
 
+func (a *AferoAdapter) observe(operation string, start time.Time, err error) {
+	protocol, ok := a.ctx.Value("protocol").(string)
+	if !ok {
+		protocol = metrics.ProtocolFTP
+	}
+	metrics.ObserveOperation(protocol, operation, start, err)
+}
+
 func (a *AferoAdapter) Create(_ string) (afero.File, error) {
 	// See also GetHandle
 	return nil, errs.NotImplement
 }
 
 func (a *AferoAdapter) Mkdir(name string, _ os.FileMode) error {
-	return Mkdir(a.ctx, name)
+	start := time.Now()
+	err := Mkdir(a.ctx, name)
+	a.observe("mkdir", start, err)
+	return err
 }
 
 func (a *AferoAdapter) MkdirAll(path string, perm os.FileMode) error {
@@ -45,7 +57,10 @@ func (a *AferoAdapter) OpenFile(_ string, _ int, _ os.FileMode) (afero.File, err
 }
 
 func (a *AferoAdapter) Remove(name string) error {
-	return Remove(a.ctx, name)
+	start := time.Now()
+	err := Remove(a.ctx, name)
+	a.observe("remove", start, err)
+	return err
 }
 
 func (a *AferoAdapter) RemoveAll(path string) error {
@@ -53,11 +68,17 @@ func (a *AferoAdapter) RemoveAll(path string) error {
 }
 
 func (a *AferoAdapter) Rename(oldName, newName string) error {
-	return Rename(a.ctx, oldName, newName)
+	start := time.Now()
+	err := Rename(a.ctx, oldName, newName)
+	a.observe("rename", start, err)
+	return err
 }
 
 func (a *AferoAdapter) Stat(name string) (os.FileInfo, error) {
-	return Stat(a.ctx, name)
+	start := time.Now()
+	info, err := Stat(a.ctx, name)
+	a.observe("stat", start, err)
+	return info, err
 }
 
 func (a *AferoAdapter) Name() string {
@@ -77,10 +98,24 @@ func (a *AferoAdapter) Chtimes(_ string, _ time.Time, _ time.Time) error {
 }
 
 func (a *AferoAdapter) ReadDir(name string) ([]os.FileInfo, error) {
-	return List(a.ctx, name)
+	start := time.Now()
+	infos, err := List(a.ctx, name)
+	a.observe("list", start, err)
+	return infos, err
 }
 
 func (a *AferoAdapter) GetHandle(name string, flags int, offset int64) (ftpserver.FileTransfer, error) {
+	start := time.Now()
+	operation := "download"
+	if (flags & os.O_WRONLY) != 0 {
+		operation = "upload"
+	}
+	ft, err := a.getHandle(name, flags, offset)
+	a.observe(operation, start, err)
+	return ft, err
+}
+
+func (a *AferoAdapter) getHandle(name string, flags int, offset int64) (ftpserver.FileTransfer, error) {
 	fileSize := a.nextFileSize
 	a.nextFileSize = 0
 	if (flags & os.O_SYNC) != 0 {
@@ -27,7 +27,7 @@ type FileUploadProxy struct {
 	trunc  bool
 }
 
-func uploadAuth(ctx context.Context, path string) error {
+func uploadAuth(ctx context.Context, path string, size int64) error {
 	user := ctx.Value("user").(*model.User)
 	meta, err := op.GetNearestMeta(stdpath.Dir(path))
 	if err != nil {
@@ -39,11 +39,13 @@ func uploadAuth(ctx context.Context, path string) error {
 		((user.CanFTPManage() && user.CanWrite()) || common.CanWrite(meta, stdpath.Dir(path)))) {
 		return errs.PermissionDenied
 	}
-	return nil
+	return fs.CheckUploadLimit(ctx, path, size)
 }
 
 func OpenUpload(ctx context.Context, path string, trunc bool) (*FileUploadProxy, error) {
-	err := uploadAuth(ctx, path)
+	// the size is unknown until close, where the put checks it again,
+	// a user already out of quota is rejected before the transfer
+	err := uploadAuth(ctx, path, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +115,7 @@ type FileUploadWithLengthProxy struct {
 }
 
 func OpenUploadWithLength(ctx context.Context, path string, trunc bool, length int64) (*FileUploadWithLengthProxy, error) {
-	err := uploadAuth(ctx, path)
+	err := uploadAuth(ctx, path, length)
 	if err != nil {
 		return nil, err
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/webhook"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pquerna/otp/totp"
@@ -85,6 +86,15 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	}
 	common.SuccessResp(c, gin.H{"token": token})
 	loginCache.Del(ip)
+	emitLogin(c, user, "password")
+}
+
+func emitLogin(c *gin.Context, user *model.User, method string) {
+	webhook.Emit(webhook.UserLogin, webhook.LoginData{
+		Username: user.Username,
+		IP:       c.ClientIP(),
+		Method:   method,
+	})
 }
 
 type UserResp struct {
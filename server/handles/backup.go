@@ -0,0 +1,76 @@
+package handles
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportBackupReq struct {
+	Parts    []string `json:"parts"`
+	Password string   `json:"password"`
+}
+
+func ExportBackup(c *gin.Context) {
+	var req ExportBackupReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	b, err := backup.Export(req.Parts)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	data, err := backup.Marshal(b, req.Password)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	filename := fmt.Sprintf("alist-backup-%s.bak", time.Now().Format("20060102150405"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(200, "application/octet-stream", data)
+}
+
+// ImportBackup imports the uploaded backup file, parts are comma separated
+func ImportBackup(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	b, err := backup.Unmarshal(data, c.PostForm("password"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	opts := backup.ImportOptions{
+		Mode: c.PostForm("mode"),
+		Live: true,
+	}
+	if parts := c.PostForm("parts"); parts != "" {
+		opts.Parts = strings.Split(parts, ",")
+	}
+	res, err := backup.Import(c, b, opts)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, res)
+}
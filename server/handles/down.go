@@ -86,7 +86,7 @@ func Proxy(c *gin.Context) {
 				return
 			}
 		}
-		link, file, err := fs.Link(c, rawPath, model.LinkArgs{
+		link, file, done, err := fs.LinkForProxy(c, rawPath, model.LinkArgs{
 			Header:  c.Request.Header,
 			Type:    c.Query("type"),
 			HttpReq: c.Request,
@@ -95,6 +95,7 @@ func Proxy(c *gin.Context) {
 			common.ErrorResp(c, err, 500)
 			return
 		}
+		defer done()
 		if link.URL != "" && setting.GetBool(conf.ForwardDirectLinkParams) {
 			query := c.Request.URL.Query()
 			for _, v := range conf.SlicesMap[conf.IgnoreDirectLinkParams] {
@@ -0,0 +1,80 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type ScanDuplicatesReq struct {
+	Paths   []string `json:"paths" binding:"required"`
+	MinSize int64    `json:"min_size"`
+}
+
+func ScanDuplicates(c *gin.Context) {
+	var req ScanDuplicatesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	t, err := fs.FindDuplicates(c, req.Paths, req.MinSize)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{"task": getTaskInfo(t)})
+}
+
+func ListDuplicateGroups(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	groups, total, err := op.GetDuplicateGroups(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: groups,
+		Total:   total,
+	})
+}
+
+type DuplicateFilesReq struct {
+	Ids []uint `json:"ids" binding:"required"`
+}
+
+// RemoveDuplicates removes the files, returns the errors by file id
+func RemoveDuplicates(c *gin.Context) {
+	var req DuplicateFilesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	retErrs, err := fs.RemoveDuplicates(c, req.Ids)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, retErrs)
+}
+
+// ReplaceDuplicates replaces the files with pointers containing the path of a kept file, returns the errors by file id
+func ReplaceDuplicates(c *gin.Context) {
+	var req DuplicateFilesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	retErrs, err := fs.ReplaceDuplicates(c, req.Ids)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, retErrs)
+}
@@ -0,0 +1,134 @@
+package handles
+
+import (
+	stdpath "path"
+	"sort"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type FsDuReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+type DuChild struct {
+	Name   string `json:"name"`
+	IsDir  bool   `json:"is_dir"`
+	Size   int64  `json:"size"`
+	Files  int64  `json:"files"`
+	Dirs   int64  `json:"dirs"`
+	Cached bool   `json:"cached"` // whether the size of the dir has been calculated
+}
+
+type FsDuResp struct {
+	*model.DirSize
+	Cached   bool      `json:"cached"`
+	Children []DuChild `json:"children"`
+}
+
+// FsDu returns the calculated size of the dir and its children, largest first
+func FsDu(c *gin.Context) {
+	var req FsDuReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	objs, err := fs.List(c, reqPath, &fs.ListArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	var dirs []string
+	for _, obj := range objs {
+		if obj.IsDir() {
+			dirs = append(dirs, stdpath.Join(reqPath, obj.GetName()))
+		}
+	}
+	sizes, err := op.GetDirSizes(append(dirs, reqPath))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := FsDuResp{DirSize: &model.DirSize{Path: reqPath}, Children: make([]DuChild, 0, len(objs))}
+	if s, ok := sizes[reqPath]; ok {
+		resp.DirSize, resp.Cached = &s, true
+	}
+	for _, obj := range objs {
+		child := DuChild{Name: obj.GetName(), IsDir: obj.IsDir(), Size: obj.GetSize()}
+		if s, ok := sizes[stdpath.Join(reqPath, obj.GetName())]; ok && obj.IsDir() {
+			child.Size, child.Files, child.Dirs, child.Cached = s.Size, s.Files, s.Dirs, true
+		}
+		resp.Children = append(resp.Children, child)
+	}
+	sort.SliceStable(resp.Children, func(i, j int) bool {
+		return resp.Children[i].Size > resp.Children[j].Size
+	})
+	common.SuccessResp(c, resp)
+}
+
+type FsDuCalcReq struct {
+	Path        string `json:"path" form:"path"`
+	Password    string `json:"password" form:"password"`
+	Concurrency int    `json:"concurrency" form:"concurrency"`
+}
+
+// FsDuCalc adds a task calculating the size of the dir and all dirs under it
+func FsDuCalc(c *gin.Context) {
+	var req FsDuCalcReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	t, err := fs.Du(c, reqPath, req.Concurrency)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{"task": getTaskInfo(t)})
+}
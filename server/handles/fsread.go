@@ -17,6 +17,7 @@ import (
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 type ListReq struct {
@@ -94,8 +95,12 @@ func FsList(c *gin.Context) {
 	if err == nil {
 		provider = storage.GetStorage().Driver
 	}
+	content := toObjsResp(objs, reqPath, isEncrypt(meta, reqPath))
+	if setting.GetBool(conf.ShowDirSize) {
+		fillDirSizes(content, reqPath)
+	}
 	common.SuccessResp(c, FsListResp{
-		Content:  toObjsResp(objs, reqPath, isEncrypt(meta, reqPath)),
+		Content:  content,
 		Total:    int64(total),
 		Readme:   getReadme(meta, reqPath),
 		Header:   getHeader(meta, reqPath),
@@ -225,6 +230,26 @@ func toObjsResp(objs []model.Obj, parent string, encrypt bool) []ObjResp {
 	return resp
 }
 
+// fillDirSizes sets the size of the dirs that have been calculated by du
+func fillDirSizes(objs []ObjResp, parent string) {
+	var dirs []string
+	for _, obj := range objs {
+		if obj.IsDir {
+			dirs = append(dirs, stdpath.Join(parent, obj.Name))
+		}
+	}
+	sizes, err := op.GetDirSizes(dirs)
+	if err != nil {
+		log.Warnf("failed get dir sizes of [%s]: %+v", parent, err)
+		return
+	}
+	for i := range objs {
+		if s, ok := sizes[stdpath.Join(parent, objs[i].Name)]; ok && objs[i].IsDir {
+			objs[i].Size = s.Size
+		}
+	}
+}
+
 type FsGetReq struct {
 	Path     string `json:"path" form:"path"`
 	Password string `json:"password" form:"password"`
@@ -237,6 +262,7 @@ type FsGetResp struct {
 	Header   string    `json:"header"`
 	Provider string    `json:"provider"`
 	Related  []ObjResp `json:"related"`
+	Cid      string    `json:"cid,omitempty"`
 }
 
 func FsGet(c *gin.Context) {
@@ -323,6 +349,7 @@ func FsGet(c *gin.Context) {
 	}
 	parentMeta, _ := op.GetNearestMeta(parentPath)
 	thumb, _ := model.GetThumb(obj)
+	cid, _ := model.GetCID(obj)
 	common.SuccessResp(c, FsGetResp{
 		ObjResp: ObjResp{
 			Name:        obj.GetName(),
@@ -341,6 +368,7 @@ func FsGet(c *gin.Context) {
 		Header:   getHeader(meta, reqPath),
 		Provider: provider,
 		Related:  toObjsResp(related, parentPath, isEncrypt(parentMeta, parentPath)),
+		Cid:      cid,
 	})
 }
 
@@ -388,6 +416,10 @@ func FsOther(c *gin.Context) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
+	if !user.CanWrite() && !common.CanWrite(meta, req.Path) && fs.IsWriteOther(req.Path, req.Method) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	res, err := fs.Other(c, req.FsOtherArgs)
 	if err != nil {
 		common.ErrorResp(c, err, 500)
@@ -0,0 +1,144 @@
+package handles
+
+import (
+	stdpath "path"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type TrashListReq struct {
+	model.PageReq
+	All bool `json:"all" form:"all"`
+}
+
+func FsTrashList(c *gin.Context) {
+	var req TrashListReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	userId := user.ID
+	if req.All && user.IsAdmin() {
+		userId = 0
+	}
+	items, total, err := op.GetTrashItems(userId, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: items,
+		Total:   total,
+	})
+}
+
+type TrashItemsReq struct {
+	Ids []uint `json:"ids"`
+}
+
+// getTrashItems returns the trash items which the user is allowed to operate
+func getTrashItems(c *gin.Context) ([]*model.TrashItem, bool) {
+	var req TrashItemsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return nil, false
+	}
+	user := c.MustGet("user").(*model.User)
+	if !user.CanRemove() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return nil, false
+	}
+	items := make([]*model.TrashItem, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		item, err := op.GetTrashItemById(id)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return nil, false
+		}
+		if item.UserId != user.ID && !user.IsAdmin() {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return nil, false
+		}
+		items = append(items, item)
+	}
+	return items, true
+}
+
+func FsTrashRestore(c *gin.Context) {
+	items, ok := getTrashItems(c)
+	if !ok {
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	for _, item := range items {
+		// the permissions may have changed since the object was removed
+		if !canRestore(user, item.OriginalPath) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	for _, item := range items {
+		if err := fs.RestoreTrashItem(c, item); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
+
+// canRestore checks the user can write to the original dir of the object
+func canRestore(user *model.User, path string) bool {
+	if !utils.IsSubPath(user.BasePath, path) {
+		return false
+	}
+	if user.CanWrite() {
+		return true
+	}
+	dir := stdpath.Dir(path)
+	meta, err := op.GetNearestMeta(dir)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return false
+	}
+	return common.CanWrite(meta, dir)
+}
+
+func FsTrashDelete(c *gin.Context) {
+	items, ok := getTrashItems(c)
+	if !ok {
+		return
+	}
+	for _, item := range items {
+		if err := fs.DeleteTrashItem(c, item); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
+
+func FsTrashEmpty(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if !user.CanRemove() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	userId := user.ID
+	if all, _ := strconv.ParseBool(c.Query("all")); all && user.IsAdmin() {
+		userId = 0
+	}
+	if err := fs.EmptyTrash(c, userId); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,306 @@
+package handles
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	tusVersion              = "1.0.0"
+	tusExtensions           = "creation,termination,checksum"
+	tusChecksumAlgorithms   = "md5,sha1,sha256"
+	tusStatusChecksumFailed = 460
+)
+
+// tus clients depend on the status code, so errors are not wrapped in common.Resp
+func tusError(c *gin.Context, code int, err error) {
+	if code >= 500 {
+		log.Errorf("tus: %+v", err)
+	}
+	c.Header("Tus-Resumable", tusVersion)
+	c.String(code, err.Error())
+	c.Abort()
+}
+
+func tusNewHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "md5":
+		return md5.New()
+	case "sha1":
+		return sha1.New()
+	case "sha256":
+		return sha256.New()
+	}
+	return nil
+}
+
+// tusParseMetadata parses Upload-Metadata, keys and base64 encoded values are separated by space
+func tusParseMetadata(header string) map[string]string {
+	meta := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), " ", 2)
+		if kv[0] == "" {
+			continue
+		}
+		if len(kv) == 1 {
+			meta[kv[0]] = ""
+			continue
+		}
+		v, err := base64.StdEncoding.DecodeString(kv[1])
+		if err == nil {
+			meta[kv[0]] = string(v)
+		}
+	}
+	return meta
+}
+
+func TusOptions(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Checksum-Algorithm", tusChecksumAlgorithms)
+	c.Status(http.StatusNoContent)
+}
+
+// TusCreate creates an upload, the destination is given by File-Path header and checked by middlewares.FsUp
+func TusCreate(c *gin.Context) {
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		tusError(c, http.StatusPreconditionFailed, errors.New("unsupported tus version"))
+		return
+	}
+	size, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		tusError(c, http.StatusBadRequest, errors.New("invalid Upload-Length"))
+		return
+	}
+	path, err := url.PathUnescape(c.GetHeader("File-Path"))
+	if err != nil {
+		tusError(c, http.StatusBadRequest, err)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	path, err = user.JoinPath(path)
+	if err != nil {
+		tusError(c, http.StatusForbidden, err)
+		return
+	}
+	if err = fs.CheckUploadLimit(c, path, size); err != nil {
+		tusError(c, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	if err = os.MkdirAll(fs.TusTempDir(), 0777); err != nil {
+		tusError(c, http.StatusInternalServerError, err)
+		return
+	}
+	id := uuid.NewString()
+	tempFile := filepath.Join(fs.TusTempDir(), id)
+	f, err := os.Create(tempFile)
+	if err != nil {
+		tusError(c, http.StatusInternalServerError, err)
+		return
+	}
+	_ = f.Close()
+	err = op.CreateTusUpload(&model.TusUpload{
+		ID:       id,
+		UserId:   user.ID,
+		Path:     path,
+		Size:     size,
+		Metadata: c.GetHeader("Upload-Metadata"),
+		TempFile: tempFile,
+	})
+	if err != nil {
+		_ = os.Remove(tempFile)
+		tusError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Location", stdpath.Join(c.Request.URL.Path, id))
+	c.Status(http.StatusCreated)
+}
+
+func getTusUpload(c *gin.Context) (*model.TusUpload, bool) {
+	u, err := op.GetTusUploadById(c.Param("id"))
+	if err != nil {
+		tusError(c, http.StatusNotFound, err)
+		return nil, false
+	}
+	user := c.MustGet("user").(*model.User)
+	if u.UserId != user.ID {
+		tusError(c, http.StatusForbidden, errs.PermissionDenied)
+		return nil, false
+	}
+	return u, true
+}
+
+func TusHead(c *gin.Context) {
+	u, ok := getTusUpload(c)
+	if !ok {
+		return
+	}
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(u.Size, 10))
+	if u.Metadata != "" {
+		c.Header("Upload-Metadata", u.Metadata)
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+}
+
+func TusPatch(c *gin.Context) {
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		tusError(c, http.StatusPreconditionFailed, errors.New("unsupported tus version"))
+		return
+	}
+	if c.ContentType() != "application/offset+octet-stream" {
+		tusError(c, http.StatusUnsupportedMediaType, errors.New("invalid Content-Type"))
+		return
+	}
+	// only existing uploads are locked, so no lock is left for unknown ids
+	if _, ok := getTusUpload(c); !ok {
+		return
+	}
+	mu, ok := fs.TryLockTusUpload(c.Param("id"))
+	if !ok {
+		tusError(c, http.StatusConflict, errors.New("the upload is being written by another request"))
+		return
+	}
+	defer mu.Unlock()
+	// the offset is read after locking, so it's the one left by the last request
+	u, ok := getTusUpload(c)
+	if !ok {
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset != u.Offset {
+		tusError(c, http.StatusConflict, errors.New("mismatched Upload-Offset"))
+		return
+	}
+	var h hash.Hash
+	var expected []byte
+	if checksum := c.GetHeader("Upload-Checksum"); checksum != "" {
+		algorithm, value, _ := strings.Cut(checksum, " ")
+		if h = tusNewHash(algorithm); h == nil {
+			tusError(c, http.StatusBadRequest, errors.Errorf("unsupported checksum algorithm: %s", algorithm))
+			return
+		}
+		if expected, err = base64.StdEncoding.DecodeString(value); err != nil {
+			tusError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	f, err := os.OpenFile(u.TempFile, os.O_WRONLY, 0666)
+	if err != nil {
+		tusError(c, http.StatusInternalServerError, err)
+		return
+	}
+	defer f.Close()
+	if _, err = f.Seek(u.Offset, io.SeekStart); err != nil {
+		tusError(c, http.StatusInternalServerError, err)
+		return
+	}
+	var w io.Writer = f
+	if h != nil {
+		w = io.MultiWriter(f, h)
+	}
+	n, copyErr := io.Copy(w, io.LimitReader(c.Request.Body, u.Size-u.Offset))
+	if h != nil {
+		// the chunk must be discarded if it can't be verified
+		if copyErr != nil {
+			_ = f.Truncate(u.Offset)
+			tusError(c, http.StatusInternalServerError, copyErr)
+			return
+		}
+		if !bytes.Equal(h.Sum(nil), expected) {
+			_ = f.Truncate(u.Offset)
+			tusError(c, tusStatusChecksumFailed, errors.New("checksum mismatch"))
+			return
+		}
+	}
+	if err = op.UpdateTusUploadOffset(u.ID, u.Offset+n); err != nil {
+		tusError(c, http.StatusInternalServerError, err)
+		return
+	}
+	u.Offset += n
+	if copyErr != nil {
+		tusError(c, http.StatusInternalServerError, copyErr)
+		return
+	}
+	if u.Offset == u.Size {
+		if err = tusFinish(c, u); err != nil {
+			tusError(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	c.Status(http.StatusNoContent)
+}
+
+// tusFinish hands the completed file to an upload task
+func tusFinish(c *gin.Context, u *model.TusUpload) error {
+	f, err := os.Open(u.TempFile)
+	if err != nil {
+		return err
+	}
+	dir, name := stdpath.Split(u.Path)
+	mimetype := tusParseMetadata(u.Metadata)["filetype"]
+	if mimetype == "" {
+		mimetype = utils.GetMimeType(name)
+	}
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     u.Size,
+			Modified: time.Now(),
+		},
+		Mimetype:     mimetype,
+		WebPutAsTask: true,
+	}
+	s.SetTmpFile(f)
+	s.Closers.Add(f)
+	if _, err = fs.PutAsTask(c, dir, s); err != nil {
+		// the staged file is removed by closing the stream, so the upload can't be resumed
+		_ = s.Close()
+		_ = fs.ForgetTusUpload(u.ID)
+		return err
+	}
+	return fs.ForgetTusUpload(u.ID)
+}
+
+func TusDelete(c *gin.Context) {
+	u, ok := getTusUpload(c)
+	if !ok {
+		return
+	}
+	if err := fs.DeleteTusUpload(u); err != nil {
+		tusError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Header("Tus-Resumable", tusVersion)
+	c.Status(http.StatusNoContent)
+}
@@ -8,11 +8,14 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 func getLastModified(c *gin.Context) time.Time {
@@ -47,16 +50,25 @@ func FsStream(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	obj := &model.Object{
+		Name:     name,
+		Size:     size,
+		Modified: getLastModified(c),
+	}
 	s := &stream.FileStream{
-		Obj: &model.Object{
-			Name:     name,
-			Size:     size,
-			Modified: getLastModified(c),
-		},
+		Obj:          obj,
 		Reader:       c.Request.Body,
 		Mimetype:     c.GetHeader("Content-Type"),
 		WebPutAsTask: asTask,
 	}
+	if fileHash := c.GetHeader("File-Hash"); fileHash != "" {
+		ht, sum, err := common.ParseFileHash(fileHash)
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		s.Reader = stream.NewVerifyReader(c.Request.Body, ht, sum, size)
+	}
 	var t task.TaskExtensionInfo
 	if asTask {
 		t, err = fs.PutAsTask(c, dir, s)
@@ -65,7 +77,7 @@ func FsStream(c *gin.Context) {
 	}
 	defer c.Request.Body.Close()
 	if err != nil {
-		common.ErrorResp(c, err, 500)
+		common.ErrorResp(c, err, putErrStatus(err))
 		return
 	}
 	if t == nil {
@@ -112,12 +124,30 @@ func FsForm(c *gin.Context) {
 	}
 	defer f.Close()
 	dir, name := stdpath.Split(path)
+	obj := &model.Object{
+		Name:     name,
+		Size:     file.Size,
+		Modified: getLastModified(c),
+	}
+	if fileHash := c.GetHeader("File-Hash"); fileHash != "" {
+		ht, sum, err := common.ParseFileHash(fileHash)
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		// the form file is received already, so verify it before upload
+		actual, err := utils.HashFile(ht, f)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		if actual != sum {
+			common.ErrorResp(c, errors.Wrapf(errs.ChecksumMismatch, "expected %s, got %s", sum, actual), 400)
+			return
+		}
+	}
 	s := stream.FileStream{
-		Obj: &model.Object{
-			Name:     name,
-			Size:     file.Size,
-			Modified: getLastModified(c),
-		},
+		Obj:          obj,
 		Reader:       f,
 		Mimetype:     file.Header.Get("Content-Type"),
 		WebPutAsTask: asTask,
@@ -137,7 +167,7 @@ func FsForm(c *gin.Context) {
 		err = fs.PutDirectly(c, dir, ss, true)
 	}
 	if err != nil {
-		common.ErrorResp(c, err, 500)
+		common.ErrorResp(c, err, putErrStatus(err))
 		return
 	}
 	if t == nil {
@@ -148,3 +178,11 @@ func FsForm(c *gin.Context) {
 		"task": getTaskInfo(t),
 	})
 }
+
+// putErrStatus is 403 for the files rejected by upload limits
+func putErrStatus(err error) int {
+	if errors.Is(err, errs.QuotaExceeded) || errors.Is(err, errs.FileTooLarge) || errors.Is(err, errs.ExtNotAllowed) {
+		return 403
+	}
+	return 500
+}
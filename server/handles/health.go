@@ -0,0 +1,53 @@
+package handles
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/health"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func GetStoragesHealth(c *gin.Context) {
+	if idStr := c.Query("id"); idStr != "" {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		status, ok := health.Get(uint(id))
+		if !ok {
+			common.ErrorStrResp(c, "storage has not been checked yet", 404)
+			return
+		}
+		common.SuccessResp(c, status)
+		return
+	}
+	statuses := health.GetAll()
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].MountPath < statuses[j].MountPath
+	})
+	common.SuccessResp(c, statuses)
+}
+
+func CheckStorageHealth(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	storage, err := db.GetStorageById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	storageDriver, err := op.GetStorageByMountPath(storage.MountPath)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, health.Check(c, storageDriver))
+}
@@ -119,6 +119,7 @@ func loginLdap(c *gin.Context, req *LoginReq) {
 	}
 	common.SuccessResp(c, gin.H{"token": token})
 	loginCache.Del(ip)
+	emitLogin(c, user, "ldap")
 }
 
 func ladpRegister(username string) (*model.User, error) {
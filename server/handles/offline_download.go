@@ -1,6 +1,10 @@
 package handles
 
 import (
+	"io"
+	stdpath "path"
+	"strings"
+
 	_115 "github.com/alist-org/alist/v3/drivers/115"
 	"github.com/alist-org/alist/v3/drivers/pikpak"
 	"github.com/alist-org/alist/v3/drivers/thunder"
@@ -245,10 +249,13 @@ func OfflineDownloadTools(c *gin.Context) {
 }
 
 type AddOfflineDownloadReq struct {
-	Urls         []string `json:"urls"`
-	Path         string   `json:"path"`
-	Tool         string   `json:"tool"`
-	DeletePolicy string   `json:"delete_policy"`
+	Urls         []string          `json:"urls"`
+	Path         string            `json:"path"`
+	Tool         string            `json:"tool"`
+	DeletePolicy string            `json:"delete_policy"`
+	Headers      map[string]string `json:"headers"`
+	Checksum     string            `json:"checksum"`
+	Files        []string          `json:"files"`
 }
 
 func AddOfflineDownload(c *gin.Context) {
@@ -275,6 +282,9 @@ func AddOfflineDownload(c *gin.Context) {
 			DstDirPath:   reqPath,
 			Tool:         req.Tool,
 			DeletePolicy: tool.DeletePolicy(req.DeletePolicy),
+			Headers:      req.Headers,
+			Checksum:     req.Checksum,
+			Files:        req.Files,
 		})
 		if err != nil {
 			common.ErrorResp(c, err, 500)
@@ -288,3 +298,86 @@ func AddOfflineDownload(c *gin.Context) {
 		"tasks": getTaskInfos(tasks),
 	})
 }
+
+type ImportOfflineDownloadReq struct {
+	Path         string `json:"path" form:"path"`
+	Tool         string `json:"tool" form:"tool"`
+	DeletePolicy string `json:"delete_policy" form:"delete_policy"`
+	Format       string `json:"format" form:"format"`
+	Content      string `json:"content" form:"content"`
+}
+
+type ImportOfflineDownloadResult struct {
+	tool.ImportEntry
+	Task *TaskInfo `json:"task,omitempty"`
+}
+
+// ImportOfflineDownload adds the downloads of a metalink, aria2 input file or csv/tsv,
+// given as the content field or a multipart file, and reports the result of every entry
+func ImportOfflineDownload(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if !user.CanAddOfflineDownloadTasks() {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	var req ImportOfflineDownloadReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	var r io.Reader = strings.NewReader(req.Content)
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		defer f.Close()
+		r = f
+	}
+	entries, err := tool.ParseImport(req.Format, r)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	results := make([]ImportOfflineDownloadResult, len(entries))
+	failed := 0
+	for i, e := range entries {
+		results[i].ImportEntry = e
+		if e.Error == "" {
+			results[i].Task, err = importEntry(c, user, &req, &e)
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}
+		if results[i].Error != "" {
+			failed++
+		}
+	}
+	common.SuccessResp(c, gin.H{
+		"results":   results,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+	})
+}
+
+func importEntry(c *gin.Context, user *model.User, req *ImportOfflineDownloadReq, e *tool.ImportEntry) (*TaskInfo, error) {
+	dstDirPath, err := user.JoinPath(stdpath.Join(req.Path, e.Dir))
+	if err != nil {
+		return nil, err
+	}
+	t, err := tool.AddURL(c, &tool.AddURLArgs{
+		URL:          e.URL,
+		DstDirPath:   dstDirPath,
+		Tool:         req.Tool,
+		DeletePolicy: tool.DeletePolicy(req.DeletePolicy),
+		Headers:      e.Headers,
+		Checksum:     e.Checksum,
+		Filename:     e.Name,
+	})
+	if err != nil || t == nil {
+		return nil, err
+	}
+	info := getTaskInfo(t)
+	return &info, nil
+}
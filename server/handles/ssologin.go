@@ -261,6 +261,8 @@ func OIDCLoginCallback(c *gin.Context) {
 		token, err := common.GenerateToken(user)
 		if err != nil {
 			common.ErrorResp(c, err, 400)
+		} else {
+			emitLogin(c, user, "sso")
 		}
 		if useCompatibility {
 			c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token)
@@ -427,6 +429,8 @@ func SSOLoginCallback(c *gin.Context) {
 	token, err := common.GenerateToken(user)
 	if err != nil {
 		common.ErrorResp(c, err, 400)
+	} else {
+		emitLogin(c, user, "sso")
 	}
 	if usecompatibility {
 		c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token)
@@ -0,0 +1,170 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/subscription"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListSubscriptions(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	subscriptions, total, err := op.GetSubscriptions(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: subscriptions,
+		Total:   total,
+	})
+}
+
+func GetSubscription(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	s, err := op.GetSubscriptionById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, s)
+}
+
+func CreateSubscription(c *gin.Context) {
+	var req model.Subscription
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, err := tool.Tools.Get(req.Tool); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.CreateSubscription(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	subscription.Schedule(&req)
+	common.SuccessResp(c, gin.H{"id": req.ID})
+}
+
+func UpdateSubscription(c *gin.Context) {
+	var req model.Subscription
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, err := tool.Tools.Get(req.Tool); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.UpdateSubscription(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	subscription.Schedule(&req)
+	common.SuccessResp(c)
+}
+
+func DeleteSubscription(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	subscription.Unschedule(uint(id))
+	if err := op.DeleteSubscriptionById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// CheckSubscription polls the feed now and returns the items added
+func CheckSubscription(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	s, err := op.GetSubscriptionById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	items, err := subscription.Check(c, s)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, items)
+}
+
+type ListSubscriptionItemsReq struct {
+	model.PageReq
+	ID uint `json:"id" form:"id" binding:"required"`
+}
+
+func ListSubscriptionItems(c *gin.Context) {
+	var req ListSubscriptionItemsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	items, total, err := op.GetSubscriptionItems(req.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: items,
+		Total:   total,
+	})
+}
+
+// RetriggerSubscriptionItem adds the offline download of a history item again
+func RetriggerSubscriptionItem(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	item, err := subscription.Retrigger(c, uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, item)
+}
+
+func ClearSubscriptionItems(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.ClearSubscriptionItems(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
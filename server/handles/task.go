@@ -218,6 +218,18 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager *tache.Mana
 func SetupTaskRoute(g *gin.RouterGroup) {
 	taskRoute(g.Group("/upload"), fs.UploadTaskManager)
 	taskRoute(g.Group("/copy"), fs.CopyTaskManager)
+	syncGroup := g.Group("/sync")
+	taskRoute(syncGroup, fs.SyncTaskManager)
+	syncGroup.GET("/report", getTargetedHandler(fs.SyncTaskManager, func(c *gin.Context, task *fs.SyncTask) {
+		common.SuccessResp(c, task.GetReport())
+	}))
+	taskRoute(g.Group("/duplicate"), fs.DuplicateTaskManager)
+	taskRoute(g.Group("/du"), fs.DuTaskManager)
+	otherGroup := g.Group("/other")
+	taskRoute(otherGroup, fs.OtherTaskManager)
+	otherGroup.GET("/result", getTargetedHandler(fs.OtherTaskManager, func(c *gin.Context, task *fs.OtherTask) {
+		common.SuccessResp(c, task.GetResult())
+	}))
 	taskRoute(g.Group("/offline_download"), tool.DownloadTaskManager)
 	taskRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
 }
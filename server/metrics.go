@@ -0,0 +1,111 @@
+package server
+
+import (
+	"crypto/subtle"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/xhofe/tache"
+)
+
+var registerCollectorsOnce sync.Once
+
+func Metrics(g *gin.RouterGroup) {
+	registerCollectorsOnce.Do(func() {
+		metrics.Registry.MustRegister(&storageCollector{}, &taskCollector{})
+	})
+	h := promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})
+	g.GET("/metrics", func(c *gin.Context) {
+		if token := conf.Conf.Metrics.Token; token != "" {
+			bt := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(bt), []byte(token)) != 1 {
+				common.ErrorStrResp(c, "invalid metrics token", 401)
+				return
+			}
+		}
+		h.ServeHTTP(c.Writer, c.Request)
+	})
+}
+
+var storageStatusDesc = prometheus.NewDesc("alist_storage_up",
+	"Whether the storage is working (1) or not (0).", []string{"mount_path", "driver"}, nil)
+
+type storageCollector struct{}
+
+func (s *storageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storageStatusDesc
+}
+
+func (s *storageCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, storage := range op.GetAllStorages() {
+		up := 0.0
+		if storage.GetStorage().Status == op.WORK {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(storageStatusDesc, prometheus.GaugeValue, up,
+			storage.GetStorage().MountPath, storage.Config().Name)
+	}
+}
+
+var taskStateDesc = prometheus.NewDesc("alist_tasks",
+	"Number of tasks by type and state.", []string{"type", "state"}, nil)
+
+var taskStateNames = map[tache.State]string{
+	tache.StatePending:      "pending",
+	tache.StateRunning:      "running",
+	tache.StateSucceeded:    "succeeded",
+	tache.StateCanceling:    "canceling",
+	tache.StateCanceled:     "canceled",
+	tache.StateErrored:      "errored",
+	tache.StateFailing:      "failing",
+	tache.StateFailed:       "failed",
+	tache.StateWaitingRetry: "waiting_retry",
+	tache.StateBeforeRetry:  "before_retry",
+}
+
+func taskStates[T tache.Task](manager *tache.Manager[T]) map[string]int {
+	counts := make(map[string]int, len(taskStateNames))
+	for _, name := range taskStateNames {
+		counts[name] = 0
+	}
+	if manager == nil {
+		return counts
+	}
+	for _, t := range manager.GetAll() {
+		counts[taskStateNames[t.GetState()]]++
+	}
+	return counts
+}
+
+type taskCollector struct{}
+
+func (t *taskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- taskStateDesc
+}
+
+func (t *taskCollector) Collect(ch chan<- prometheus.Metric) {
+	managers := map[string]map[string]int{
+		"upload":                    taskStates(fs.UploadTaskManager),
+		"copy":                      taskStates(fs.CopyTaskManager),
+		"sync":                      taskStates(fs.SyncTaskManager),
+		"duplicate":                 taskStates(fs.DuplicateTaskManager),
+		"du":                        taskStates(fs.DuTaskManager),
+		"other":                     taskStates(fs.OtherTaskManager),
+		"offline_download":          taskStates(tool.DownloadTaskManager),
+		"offline_download_transfer": taskStates(tool.TransferTaskManager),
+	}
+	for typ, states := range managers {
+		for state, count := range states {
+			ch <- prometheus.MustNewConstMetric(taskStateDesc, prometheus.GaugeValue, float64(count), typ, state)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/setting"
 
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/sign"
@@ -27,8 +28,8 @@ func Down(c *gin.Context) {
 		}
 	}
 	c.Set("meta", meta)
-	// verify sign
-	if needSign(meta, rawPath) {
+	// verify sign, objects in trash are only reachable by links signed for admins
+	if needSign(meta, rawPath) || fs.IsInTrash(rawPath) {
 		s := c.Query("sign")
 		err = sign.Verify(rawPath, strings.TrimSuffix(s, "/"))
 		if err != nil {
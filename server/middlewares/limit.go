@@ -1,13 +1,21 @@
 package middlewares
 
 import (
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/gin-gonic/gin"
 )
 
 func MaxAllowed(n int) gin.HandlerFunc {
 	sem := make(chan struct{}, n)
-	acquire := func() { sem <- struct{}{} }
-	release := func() { <-sem }
+	acquire := func() {
+		sem <- struct{}{}
+		metrics.IncConnectionsInUse()
+	}
+	release := func() {
+		<-sem
+		metrics.DecConnectionsInUse()
+	}
+	metrics.SetConnectionsLimit(n)
 	return func(c *gin.Context) {
 		acquire()
 		defer release()
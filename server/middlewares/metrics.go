@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the count and latency of requests,
+// requests under /dav and /s3 are labeled with their own protocol
+func Metrics(protocol string) gin.HandlerFunc {
+	davPrefix := path.Join(conf.URL.Path, "/dav")
+	s3Prefix := path.Join(conf.URL.Path, "/s3")
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "others"
+		}
+		p := protocol
+		if p == metrics.ProtocolHTTP {
+			if strings.HasPrefix(route, davPrefix) {
+				p = metrics.ProtocolWebDAV
+			} else if strings.HasPrefix(route, s3Prefix) {
+				p = metrics.ProtocolS3
+			}
+		}
+		metrics.ObserveHTTPRequest(p, route, c.Request.Method, c.Writer.Status(), start)
+	}
+}
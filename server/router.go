@@ -4,6 +4,7 @@ import (
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/message"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/alist/v3/server/handles"
@@ -31,6 +32,10 @@ func Init(e *gin.Engine) {
 	g.GET("/robots.txt", handles.Robots)
 	g.GET("/i/:link_name", handles.Plist)
 	common.SecretKey = []byte(conf.Conf.JwtSecret)
+	if conf.Conf.Metrics.Enable {
+		Metrics(g)
+		g.Use(middlewares.Metrics(metrics.ProtocolHTTP))
+	}
 	g.Use(middlewares.StoragesLoaded)
 	if conf.Conf.MaxConnections > 0 {
 		g.Use(middlewares.MaxAllowed(conf.Conf.MaxConnections))
@@ -77,6 +82,9 @@ func Init(e *gin.Engine) {
 	public := api.Group("/public")
 	public.Any("/settings", handles.PublicSettings)
 	public.Any("/offline_download_tools", handles.OfflineDownloadTools)
+	// the preflight of browsers carries no token
+	api.OPTIONS("/fs/tus", handles.TusOptions)
+	api.OPTIONS("/fs/tus/:id", handles.TusOptions)
 
 	_fs(auth.Group("/fs"))
 	_task(auth.Group("/task", middlewares.AuthNotGuest))
@@ -117,6 +125,8 @@ func admin(g *gin.RouterGroup) {
 	storage.POST("/enable", handles.EnableStorage)
 	storage.POST("/disable", handles.DisableStorage)
 	storage.POST("/load_all", handles.LoadAllStorages)
+	storage.GET("/health", handles.GetStoragesHealth)
+	storage.POST("/health/check", handles.CheckStorageHealth)
 
 	driver := g.Group("/driver")
 	driver.GET("/list", handles.ListDriverInfo)
@@ -136,6 +146,46 @@ func admin(g *gin.RouterGroup) {
 	setting.POST("/set_pikpak", handles.SetPikPak)
 	setting.POST("/set_thunder", handles.SetThunder)
 
+	webhook := g.Group("/webhook")
+	webhook.GET("/list", handles.ListWebhooks)
+	webhook.GET("/get", handles.GetWebhook)
+	webhook.GET("/events", handles.ListWebhookEvents)
+	webhook.POST("/create", handles.CreateWebhook)
+	webhook.POST("/update", handles.UpdateWebhook)
+	webhook.POST("/delete", handles.DeleteWebhook)
+	webhook.POST("/test", handles.TestWebhook)
+	webhook.GET("/deliveries", handles.ListWebhookDeliveries)
+	webhook.POST("/clear_deliveries", handles.ClearWebhookDeliveries)
+
+	subscription := g.Group("/subscription")
+	subscription.GET("/list", handles.ListSubscriptions)
+	subscription.GET("/get", handles.GetSubscription)
+	subscription.POST("/create", handles.CreateSubscription)
+	subscription.POST("/update", handles.UpdateSubscription)
+	subscription.POST("/delete", handles.DeleteSubscription)
+	subscription.POST("/check", handles.CheckSubscription)
+	subscription.GET("/items", handles.ListSubscriptionItems)
+	subscription.POST("/retrigger", handles.RetriggerSubscriptionItem)
+	subscription.POST("/clear_items", handles.ClearSubscriptionItems)
+
+	syncJob := g.Group("/sync_job")
+	syncJob.GET("/list", handles.ListSyncJobs)
+	syncJob.GET("/get", handles.GetSyncJob)
+	syncJob.POST("/create", handles.CreateSyncJob)
+	syncJob.POST("/update", handles.UpdateSyncJob)
+	syncJob.POST("/delete", handles.DeleteSyncJob)
+	syncJob.POST("/run", handles.RunSyncJob)
+
+	duplicate := g.Group("/duplicate")
+	duplicate.POST("/scan", handles.ScanDuplicates)
+	duplicate.GET("/groups", handles.ListDuplicateGroups)
+	duplicate.POST("/remove", handles.RemoveDuplicates)
+	duplicate.POST("/replace", handles.ReplaceDuplicates)
+
+	backup := g.Group("/backup")
+	backup.POST("/export", handles.ExportBackup)
+	backup.POST("/import", handles.ImportBackup)
+
 	// retain /admin/task API to ensure compatibility with legacy automation scripts
 	_task(g.Group("/task"))
 
@@ -164,15 +214,30 @@ func _fs(g *gin.RouterGroup) {
 	g.POST("/move", handles.FsMove)
 	g.POST("/recursive_move", handles.FsRecursiveMove)
 	g.POST("/copy", handles.FsCopy)
+	g.POST("/sync", handles.FsSync)
+	g.Any("/du", handles.FsDu)
+	g.POST("/du/calc", handles.FsDuCalc)
 	g.POST("/remove", handles.FsRemove)
 	g.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
+	g.Any("/trash/list", handles.FsTrashList)
+	g.POST("/trash/restore", handles.FsTrashRestore)
+	g.POST("/trash/delete", handles.FsTrashDelete)
+	g.POST("/trash/empty", handles.FsTrashEmpty)
+	g.Any("/versions/list", handles.FsVersionList)
+	g.POST("/versions/restore", handles.FsVersionRestore)
 	g.PUT("/put", middlewares.FsUp, handles.FsStream)
 	g.PUT("/form", middlewares.FsUp, handles.FsForm)
+	tus := g.Group("/tus")
+	tus.POST("", middlewares.FsUp, handles.TusCreate)
+	tus.HEAD("/:id", handles.TusHead)
+	tus.PATCH("/:id", handles.TusPatch)
+	tus.DELETE("/:id", handles.TusDelete)
 	g.POST("/link", middlewares.AuthAdmin, handles.Link)
 	// g.POST("/add_aria2", handles.AddOfflineDownload)
 	// g.POST("/add_qbit", handles.AddQbittorrent)
 	// g.POST("/add_transmission", handles.SetTransmission)
 	g.POST("/add_offline_download", handles.AddOfflineDownload)
+	g.POST("/import_offline_download", handles.ImportOfflineDownload)
 }
 
 func _task(g *gin.RouterGroup) {
@@ -190,5 +255,8 @@ func Cors(r *gin.Engine) {
 
 func InitS3(e *gin.Engine) {
 	Cors(e)
+	if conf.Conf.Metrics.Enable {
+		e.Use(middlewares.Metrics(metrics.ProtocolS3))
+	}
 	S3Server(e.Group("/"))
 }
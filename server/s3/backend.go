@@ -282,6 +282,10 @@ func (b *s3Backend) PutObject(
 	log.Debugf("reqPath: %s", reqPath)
 	fmeta, _ := op.GetNearestMeta(fp)
 	ctx = context.WithValue(ctx, "meta", fmeta)
+	ctx, err = withAdmin(ctx)
+	if err != nil {
+		return result, err
+	}
 
 	_, err = fs.Get(ctx, reqPath, &fs.GetArgs{})
 	if err != nil {
@@ -379,6 +383,10 @@ func (b *s3Backend) deleteObject(ctx context.Context, bucketName, objectName str
 		return err
 	}
 
+	ctx, err = withAdmin(ctx)
+	if err != nil {
+		return err
+	}
 	fs.Remove(ctx, fp)
 	return nil
 }
@@ -40,6 +40,16 @@ func getBucketByName(name string) (Bucket, error) {
 	return Bucket{}, gofakes3.BucketNotFound(name)
 }
 
+// withAdmin puts the admin into ctx, so the writes of the s3 server are
+// accounted like the ones of the other admin jobs
+func withAdmin(ctx context.Context) (context.Context, error) {
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, "user", admin), nil
+}
+
 func getDirEntries(path string) ([]model.Obj, error) {
 	ctx := context.Background()
 	meta, _ := op.GetNearestMeta(path)
@@ -243,10 +243,11 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	storage, _ := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
 	downProxyUrl := storage.GetStorage().DownProxyUrl
 	if storage.GetStorage().WebdavNative() || (storage.GetStorage().WebdavProxy() && downProxyUrl == "") {
-		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{Header: r.Header, HttpReq: r})
+		link, _, done, err := fs.LinkForProxy(ctx, reqPath, model.LinkArgs{Header: r.Header, HttpReq: r})
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
+		defer done()
 		if storage.GetStorage().ProxyRange {
 			common.ProxyRange(link, fi.GetSize())
 		}
@@ -342,6 +343,17 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	if fsStream.Mimetype == "" {
 		fsStream.Mimetype = utils.GetMimeType(reqPath)
 	}
+	fileHash := r.Header.Get("File-Hash")
+	if fileHash == "" {
+		fileHash = r.Header.Get("OC-Checksum")
+	}
+	if fileHash != "" {
+		ht, sum, err := common.ParseFileHash(fileHash)
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+		fsStream.Reader = stream.NewVerifyReader(r.Body, ht, sum, r.ContentLength)
+	}
 	err = fs.PutDirectly(ctx, path.Dir(reqPath), fsStream)
 	if errs.IsNotFoundError(err) {
 		return http.StatusNotFound, err
@@ -349,6 +361,12 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 
 	_ = r.Body.Close()
 	_ = fsStream.Close()
+	if errors.Is(err, errs.ChecksumMismatch) {
+		return http.StatusBadRequest, err
+	}
+	if status := uploadLimitStatus(err); status != http.StatusInternalServerError {
+		return status, err
+	}
 	// TODO(rost): Returning 405 Method Not Allowed might not be appropriate.
 	if err != nil {
 		return http.StatusMethodNotAllowed, err
@@ -365,6 +383,18 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	return http.StatusCreated, nil
 }
 
+func uploadLimitStatus(err error) int {
+	switch {
+	case errors.Is(err, errs.QuotaExceeded):
+		return http.StatusInsufficientStorage
+	case errors.Is(err, errs.FileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errs.ExtNotAllowed):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
 func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request) (status int, err error) {
 	reqPath, status, err := h.stripPrefix(r.URL.Path)
 	if err != nil {